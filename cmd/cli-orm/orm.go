@@ -1,6 +1,9 @@
 package cli_orm
 
 import (
+	"context"
+	"io"
+
 	"github.com/Alexandrhub/cli-orm-gen/db"
 	"github.com/Alexandrhub/cli-orm-gen/db/dao"
 	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
@@ -21,9 +24,32 @@ func NewOrm(dbConf utils.DB, scanner scanner.Scanner, logger *zap.Logger) (*dao.
 		logger.Fatal("error init db", zap.Error(err))
 	}
 	migrator := migrate.NewMigrator(orm.DB, dbConf, scanner)
-	err = migrator.Migrate()
+	err = migrator.Migrate(context.Background())
 	if err != nil {
 		logger.Fatal("migrator err", zap.Error(err))
 	}
 	return orm.DAO, nil
 }
+
+// Diff сравнение схемы базы данных с зарегистрированными таблицами для проверок в CI,
+// отчет в формате JSON пишется в w, при расхождениях возвращается migrate.ErrDrift
+func Diff(ctx context.Context, dbConf utils.DB, scanner scanner.Scanner, w io.Writer, logger *zap.Logger, opts ...migrate.Option) error {
+	orm, err := db.NewSqlDB(dbConf, scanner, logger)
+	if err != nil {
+		return err
+	}
+	defer orm.Close()
+
+	report, err := migrate.NewMigrator(orm.DB, dbConf, scanner).Drift(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	if err = report.WriteJSON(w); err != nil {
+		return err
+	}
+	if report.HasDrift() {
+		return migrate.ErrDrift
+	}
+
+	return nil
+}
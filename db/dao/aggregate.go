@@ -0,0 +1,244 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+// AggregateFunc агрегатная функция
+type AggregateFunc string
+
+const (
+	AggCount         AggregateFunc = "COUNT"
+	AggCountDistinct AggregateFunc = "COUNT DISTINCT"
+	AggSum           AggregateFunc = "SUM"
+	AggAvg           AggregateFunc = "AVG"
+	AggMin           AggregateFunc = "MIN"
+	AggMax           AggregateFunc = "MAX"
+)
+
+// ErrEmptyAggregate ошибка агрегирующего запроса без группировки и агрегатных функций
+var ErrEmptyAggregate = errors.New("dao: aggregate requires group by columns or aggregations")
+
+// Aggregation агрегатная функция над колонкой, результат выбирается с псевдонимом Alias
+type Aggregation struct {
+	Func AggregateFunc
+	// Field колонка, для COUNT без колонки считаются все строки
+	Field string
+	Alias string
+}
+
+// Count количество строк COUNT(*)
+func Count(alias string) Aggregation {
+	return Aggregation{Func: AggCount, Alias: alias}
+}
+
+// CountDistinct количество различных значений колонки
+func CountDistinct(field, alias string) Aggregation {
+	return Aggregation{Func: AggCountDistinct, Field: field, Alias: alias}
+}
+
+// Sum сумма значений колонки
+func Sum(field, alias string) Aggregation {
+	return Aggregation{Func: AggSum, Field: field, Alias: alias}
+}
+
+// Avg среднее значение колонки
+func Avg(field, alias string) Aggregation {
+	return Aggregation{Func: AggAvg, Field: field, Alias: alias}
+}
+
+// Min минимальное значение колонки
+func Min(field, alias string) Aggregation {
+	return Aggregation{Func: AggMin, Field: field, Alias: alias}
+}
+
+// Max максимальное значение колонки
+func Max(field, alias string) Aggregation {
+	return Aggregation{Func: AggMax, Field: field, Alias: alias}
+}
+
+// Aggregate агрегирующий запрос: колонки группировки выбираются с псевдонимами,
+// совпадающими с указанием колонки, затем агрегатные функции в порядке объявления
+type Aggregate struct {
+	GroupBy      []string
+	Aggregations []Aggregation
+	// Having условия на группы, поле выражения псевдоним агрегатной функции или колонка
+	Having []utils.Expr
+}
+
+// GroupCount количество строк Count со значением Value колонки группировки
+type GroupCount[T any] struct {
+	Value T
+	Count uint64
+}
+
+// Aggregate выборка агрегатов по условию в dest: указатель на срез структур
+// с тегами db по псевдонимам или указатель на []map[string]interface{}.
+// Order условия может ссылаться на псевдонимы агрегатных функций, блокировка строк не применяется
+func (s *DAO) Aggregate(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, aggregate Aggregate, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	query, args, err := s.aggregateQuery(table, condition, aggregate, o)
+	if err != nil {
+		return err
+	}
+
+	maps, ok := dest.(*[]map[string]interface{})
+	if !ok {
+		return s.executor(ctx, o).SelectContext(ctx, dest, query, args...)
+	}
+	rows, err := s.executor(ctx, o).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err = rows.MapScan(row); err != nil {
+			return err
+		}
+		for key, value := range row {
+			if raw, ok := value.([]byte); ok {
+				row[key] = string(raw)
+			}
+		}
+		*maps = append(*maps, row)
+	}
+
+	return rows.Err()
+}
+
+// CountBy количество строк по значениям колонки field, без Order условия
+// группы упорядочены по значению
+func CountBy[T any](ctx context.Context, s *DAO, table scanner.Tabler, field string, condition utils.Condition, opts ...Option) ([]GroupCount[T], error) {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	if len(condition.Order) == 0 {
+		condition.Order = []*utils.Order{{Field: field, Asc: true}}
+	}
+	aggregate := Aggregate{GroupBy: []string{field}, Aggregations: []Aggregation{Count("count")}}
+	query, args, err := s.aggregateQuery(table, condition, aggregate, o)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.executor(ctx, o).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []GroupCount[T]
+	for rows.Next() {
+		var count GroupCount[T]
+		if err = rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// aggregateQuery построение агрегирующего запроса, соединения и область
+// мягко удаленных строк применяются как в List
+func (s *DAO) aggregateQuery(table scanner.Tabler, condition utils.Condition, aggregate Aggregate, o *options) (string, []interface{}, error) {
+	if len(aggregate.GroupBy) == 0 && len(aggregate.Aggregations) == 0 {
+		return "", nil, ErrEmptyAggregate
+	}
+
+	q := selectQuery{tables: []string{table.TableName()}}
+	if len(o.joins) > 0 {
+		var err error
+		q.tables, q.joins, err = s.joinQuery(table.TableName(), o.joins)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	groupBy := make([]string, 0, len(aggregate.GroupBy))
+	for _, ref := range aggregate.GroupBy {
+		column, err := s.column(q.tables, ref)
+		if err != nil {
+			return "", nil, err
+		}
+		groupBy = append(groupBy, column)
+		q.fields = append(q.fields, column+" AS "+s.quoteIdentifier(ref))
+	}
+	aliases := make(map[string]string, len(aggregate.Aggregations))
+	for _, aggregation := range aggregate.Aggregations {
+		if aggregation.Alias == "" {
+			return "", nil, fmt.Errorf("dao: aggregate %s requires an alias", aggregation.Func)
+		}
+		expression, err := s.aggregation(q.tables, aggregation)
+		if err != nil {
+			return "", nil, err
+		}
+		aliases[aggregation.Alias] = expression
+		q.fields = append(q.fields, expression+" AS "+s.quoteIdentifier(aggregation.Alias))
+	}
+	resolve := func(name string) (string, error) {
+		if expression, ok := aliases[name]; ok {
+			return expression, nil
+		}
+		return s.column(q.tables, name)
+	}
+
+	order := condition.Order
+	condition.Order = nil
+	condition = s.scopeDeleted(table.TableName(), condition, o)
+	queryRaw, err := s.selectBuilder(q, condition)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(groupBy) > 0 {
+		queryRaw = queryRaw.GroupBy(groupBy...)
+	}
+	for i := range aggregate.Having {
+		predicate, err := s.predicate(aggregate.Having[i], resolve)
+		if err != nil {
+			return "", nil, err
+		}
+		queryRaw = queryRaw.Having(predicate)
+	}
+	for _, item := range order {
+		direction := "DESC"
+		if item.Asc {
+			direction = "ASC"
+		}
+		expression, err := resolve(item.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		queryRaw = queryRaw.OrderBy(fmt.Sprintf("%s %s", expression, direction))
+	}
+
+	return queryRaw.ToSql()
+}
+
+// aggregation выражение SQL агрегатной функции
+func (s *DAO) aggregation(tables []string, aggregation Aggregation) (string, error) {
+	if aggregation.Func == AggCount && aggregation.Field == "" {
+		return "COUNT(*)", nil
+	}
+	column, err := s.column(tables, aggregation.Field)
+	if err != nil {
+		return "", err
+	}
+	switch aggregation.Func {
+	case AggCountDistinct:
+		return fmt.Sprintf("COUNT(DISTINCT %s)", column), nil
+	case AggCount, AggSum, AggAvg, AggMin, AggMax:
+		return fmt.Sprintf("%s(%s)", aggregation.Func, column), nil
+	}
+
+	return "", fmt.Errorf("dao: unsupported aggregate function %q", aggregation.Func)
+}
@@ -0,0 +1,157 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+)
+
+const (
+	// maxParamsPostgres ограничение количества параметров запроса postgres и mysql
+	maxParamsPostgres = 65535
+	// maxParamsSqlite ограничение SQLITE_MAX_VARIABLE_NUMBER старых сборок sqlite
+	maxParamsSqlite = 999
+)
+
+// ChunkError ошибка пачки строк [Offset, Offset+Count)
+type ChunkError struct {
+	Chunk  int
+	Offset int
+	Count  int
+	Err    error
+}
+
+// Error описание ошибки
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("dao: chunk %d (rows %d-%d): %s", e.Chunk, e.Offset, e.Offset+e.Count-1, e.Err)
+}
+
+// Unwrap исходная ошибка
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError ошибки пачек, выполненных с опцией SeparateChunks
+type BatchError struct {
+	Chunks []*ChunkError
+}
+
+// Error описание ошибок
+func (e *BatchError) Error() string {
+	messages := make([]string, 0, len(e.Chunks))
+	for i := range e.Chunks {
+		messages = append(messages, e.Chunks[i].Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap ошибки пачек
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Chunks))
+	for i := range e.Chunks {
+		errs = append(errs, e.Chunks[i])
+	}
+
+	return errs
+}
+
+// chunkSize количество строк в пачке с учетом ограничения параметров драйвера
+func (s *DAO) chunkSize(columns int) int {
+	maxParams := maxParamsPostgres
+	if s.dbConf.Driver == DriverSqlite3 {
+		maxParams = maxParamsSqlite
+	}
+	if columns < 1 {
+		return maxParams
+	}
+
+	return maxParams / columns
+}
+
+// inChunks выполнение fn по пачкам строк, по умолчанию в одной транзакции через WithTx,
+// переданная в опциях или контексте транзакция используется без фиксации.
+// С SeparateChunks в транзакции каждая пачка выполняется в точке сохранения,
+// иначе в postgres ошибка пачки прерывает транзакцию и все следующие пачки
+func (s *DAO) inChunks(ctx context.Context, rows, columns int, fn func(ctx context.Context, offset, count int) error, o *options) error {
+	size := s.chunkSize(columns)
+	if o.separateChunks {
+		state, _ := ctx.Value(txKey{}).(*txState)
+		if o.tx != nil && (state == nil || state.tx != o.tx) {
+			state = &txState{tx: o.tx}
+		}
+		var batchErr BatchError
+		for chunk, offset := 0, 0; offset < rows; chunk, offset = chunk+1, offset+size {
+			count := min(size, rows-offset)
+			run := func(ctx context.Context) error {
+				return fn(ctx, offset, count)
+			}
+			var err error
+			if state != nil {
+				err = s.withSavepoint(ctx, state, run)
+			} else {
+				err = run(ctx)
+			}
+			if err != nil {
+				batchErr.Chunks = append(batchErr.Chunks, &ChunkError{Chunk: chunk, Offset: offset, Count: count, Err: err})
+			}
+		}
+		if len(batchErr.Chunks) > 0 {
+			return &batchErr
+		}
+		return nil
+	}
+
+	run := func(ctx context.Context) error {
+		for chunk, offset := 0, 0; offset < rows; chunk, offset = chunk+1, offset+size {
+			count := min(size, rows-offset)
+			if err := fn(ctx, offset, count); err != nil {
+				return &ChunkError{Chunk: chunk, Offset: offset, Count: count, Err: err}
+			}
+		}
+		return nil
+	}
+	if rows <= size || o.tx != nil || TxFromContext(ctx) != nil {
+		return run(ctx)
+	}
+
+	return s.WithTx(ctx, run, nil)
+}
+
+// CreateMany вставка строк пачками с учетом ограничения параметров драйвера,
+// сгенерированные базой данных поля в сущностях не заполняются
+func (s *DAO) CreateMany(ctx context.Context, entities []scanner.Tabler, opts ...Option) error {
+	if len(entities) < 1 {
+		return nil
+	}
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	createFields, _, err := s.operationFields(entities[0], scanner.Create, o)
+	if err != nil {
+		return err
+	}
+
+	return s.inChunks(
+		ctx, len(entities), len(createFields), func(ctx context.Context, offset, count int) error {
+			queryRaw := s.sqlBuilder.Insert(s.dbConf.QualifiedName(entities[0].TableName())).Columns(s.quoteColumns(createFields)...)
+			for _, entity := range entities[offset : offset+count] {
+				_, createFieldsPointers, err := s.operationFields(entity, scanner.Create, o)
+				if err != nil {
+					return err
+				}
+				queryRaw = queryRaw.Values(createFieldsPointers...)
+			}
+
+			query, args, err := queryRaw.ToSql()
+			if err != nil {
+				return err
+			}
+			_, err = s.executor(ctx, o).ExecContext(ctx, query, args...)
+
+			return err
+		}, o,
+	)
+}
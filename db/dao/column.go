@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnknownColumnError ошибка ссылки на колонку, не зарегистрированную в таблице
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+// Error описание ошибки
+func (e *UnknownColumnError) Error() string {
+	return fmt.Sprintf("dao: unknown column %q in table %s", e.Column, e.Table)
+}
+
+// column проверка колонки по зарегистрированным полям таблиц запроса
+// и получение идентификатора в кавычках диалекта. Первая из tables основная:
+// ее колонки указываются без имени таблицы, колонки присоединенных таблиц
+// указываются как table.column, в запросе с соединениями все колонки квалифицируются
+func (s *DAO) column(tables []string, name string) (string, error) {
+	tableName, column := tables[0], name
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		tableName, column = name[:i], name[i+1:]
+		if !contains(tables, tableName) {
+			return "", &UnknownColumnError{Table: tableName, Column: column}
+		}
+	}
+	if _, ok := s.scanner.Table(tableName).FieldsMap[column]; !ok {
+		return "", &UnknownColumnError{Table: tableName, Column: column}
+	}
+	if len(tables) < 2 {
+		return s.quoteIdentifier(column), nil
+	}
+
+	return s.quoteIdentifier(tableName) + "." + s.quoteIdentifier(column), nil
+}
+
+// contains наличие значения в срезе
+func contains(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteIdentifier идентификатор в кавычках диалекта, postgres приводит
+// имена без кавычек к нижнему регистру, поэтому имя в кавычках приводится так же
+func (s *DAO) quoteIdentifier(name string) string {
+	if s.dbConf.Driver == DriverPostgres {
+		name = strings.ToLower(name)
+	}
+
+	return s.dbConf.QuoteIdentifier(name)
+}
+
+// quoteColumns имена колонок основной таблицы в кавычках диалекта
+func (s *DAO) quoteColumns(columns []string) []string {
+	quoted := make([]string, 0, len(columns))
+	for i := range columns {
+		quoted = append(quoted, s.quoteIdentifier(columns[i]))
+	}
+
+	return quoted
+}
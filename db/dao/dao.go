@@ -4,13 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"reflect"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
 	"github.com/Alexandrhub/cli-orm-gen/utils"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 const (
@@ -22,45 +27,152 @@ const (
 
 //go:generate mockgen -source=./sql_adapter.go -destination=../../mock/adapter_mock.go -package=mock
 type DAOFace interface {
-	Create(ctx context.Context, entity scanner.Tabler, opts ...interface{}) error
-	Upsert(ctx context.Context, entities []scanner.Tabler, opts ...interface{}) error
-	GetCount(ctx context.Context, entity scanner.Tabler, condition utils.Condition, opts ...interface{}) (uint64, error)
-	List(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, opts ...interface{}) error
-	Update(ctx context.Context, entity scanner.Tabler, condition utils.Condition, operation string, opts ...interface{}) error
+	Create(ctx context.Context, entity scanner.Tabler, opts ...Option) error
+	CreateMany(ctx context.Context, entities []scanner.Tabler, opts ...Option) error
+	Upsert(ctx context.Context, entities []scanner.Tabler, opts ...Option) error
+	GetCount(ctx context.Context, entity scanner.Tabler, condition utils.Condition, opts ...Option) (uint64, error)
+	Aggregate(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, aggregate Aggregate, opts ...Option) error
+	List(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, opts ...Option) error
+	Page(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, cursor string, size uint64, opts ...Option) (Cursors, error)
+	Iterate(ctx context.Context, table scanner.Tabler, condition utils.Condition, fn func(row scanner.Tabler) error, opts ...Option) error
+	LoadRelations(ctx context.Context, dest interface{}, table scanner.Tabler, relations []string, opts ...Option) error
+	Get(ctx context.Context, dest scanner.Tabler, condition utils.Condition, opts ...Option) error
+	Update(ctx context.Context, entity scanner.Tabler, condition utils.Condition, operation string, opts ...Option) error
+	Delete(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error
+	SoftDelete(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error
+	Restore(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error
+	WithTx(ctx context.Context, fn func(ctx context.Context) error, txOpts *sql.TxOptions) error
+	Raw(ctx context.Context, query string, args ...interface{}) *RawQuery
+	RawWith(ctx context.Context, opts []Option, query string, args ...interface{}) *RawQuery
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	ExecWith(ctx context.Context, opts []Option, query string, args ...interface{}) (sql.Result, error)
 }
 
 type DAO struct {
 	db         *sqlx.DB
+	dbConf     utils.DB
 	scanner    scanner.Scanner
 	sqlBuilder sq.StatementBuilderType
+	logger     *zap.Logger
+	cursorKey  []byte
+
+	lockWarning   sync.Once
+	cursorWarning sync.Once
 }
 
 func NewDAO(db *sqlx.DB, dbConf utils.DB, scanner scanner.Scanner) *DAO {
-	var builder sq.StatementBuilderType
+	builder := sq.StatementBuilder
 	if dbConf.Driver != "mysql" {
 		builder = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	}
 
-	return &DAO{db: db, scanner: scanner, sqlBuilder: builder}
+	return &DAO{
+		db:         db,
+		dbConf:     dbConf,
+		scanner:    scanner,
+		sqlBuilder: builder,
+		logger:     zap.NewNop(),
+		cursorKey:  cursorKey(dbConf.CursorSecret),
+	}
 }
 
-func (s *DAO) Create(ctx context.Context, table scanner.Tabler, opts ...interface{}) error {
-	createFields, createFieldsPointers := s.getFields(table, scanner.Create)
+// SetLogger логгер предупреждений DAO
+func (s *DAO) SetLogger(logger *zap.Logger) {
+	if logger != nil {
+		s.logger = logger
+	}
+}
+
+// Create вставка строки, сгенерированные базой данных поля (scanner.Autogen), которые
+// не вставляются из сущности, заполняются в ней: через RETURNING в postgres и sqlite,
+// через LastInsertId в mysql, в остальных драйверах не заполняются
+func (s *DAO) Create(ctx context.Context, table scanner.Tabler, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	createFields, createFieldsPointers, err := s.operationFields(table, scanner.Create, o)
+	if err != nil {
+		return err
+	}
+	autogenFields, autogenPointers := s.getFields(table, scanner.Autogen)
+	autogenFields, autogenPointers = generatedFields(autogenFields, autogenPointers, createFields)
+	var setID func(id int64) error
+	if len(autogenFields) > 0 && s.dbConf.Driver == DriverMysql {
+		// тип поля проверяется до вставки, чтобы не вставить строку с ошибкой
+		if setID, err = lastInsertID(autogenFields, autogenPointers); err != nil {
+			return err
+		}
+	}
 
-	queryRaw := s.sqlBuilder.Insert(table.TableName()).Columns(createFields...).Values(createFieldsPointers...)
+	queryRaw := s.sqlBuilder.Insert(s.dbConf.QualifiedName(table.TableName())).Columns(s.quoteColumns(createFields)...).Values(createFieldsPointers...)
+	returning := len(autogenFields) > 0 && (s.dbConf.Driver == DriverPostgres || s.dbConf.Driver == DriverSqlite3)
+	if returning {
+		columns := make([]string, 0, len(autogenFields))
+		for i := range autogenFields {
+			columns = append(columns, s.quoteIdentifier(autogenFields[i]))
+		}
+		queryRaw = queryRaw.Suffix("RETURNING " + strings.Join(columns, ", "))
+	}
 
 	query, args, err := queryRaw.ToSql()
 	if err != nil {
 		return err
 	}
 
-	if tx := getTransaction(opts); tx != nil {
-		_, err = tx.ExecContext(ctx, query, args...)
-	} else {
-		_, err = s.db.ExecContext(ctx, query, args...)
+	exec := s.executor(ctx, o)
+	if returning {
+		row := exec.QueryRowxContext(ctx, query, args...)
+		return row.Scan(autogenPointers...)
 	}
 
-	return err
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil || setID == nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return setID(id)
+}
+
+// generatedFields сгенерированные поля без полей, значения которых вставляются из сущности
+func generatedFields(fields []string, pointers []interface{}, createFields []string) ([]string, []interface{}) {
+	var names []string
+	var generated []interface{}
+	for i := range fields {
+		if slices.Contains(createFields, fields[i]) {
+			continue
+		}
+		names = append(names, fields[i])
+		generated = append(generated, pointers[i])
+	}
+
+	return names, generated
+}
+
+// lastInsertID заполнение сгенерированного ключа mysql, LastInsertId
+// возвращает только значение AUTO_INCREMENT, поэтому поддерживается одно целочисленное поле
+func lastInsertID(fields []string, pointers []interface{}) (func(id int64) error, error) {
+	if len(fields) > 1 {
+		return nil, fmt.Errorf("dao: mysql returns only one generated column, got %s", strings.Join(fields, ", "))
+	}
+	if scan, ok := pointers[0].(sql.Scanner); ok {
+		return func(id int64) error { return scan.Scan(id) }, nil
+	}
+
+	value := reflect.ValueOf(pointers[0])
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		switch value = value.Elem(); value.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			return func(id int64) error { value.SetInt(id); return nil }, nil
+		case reflect.Uint, reflect.Uint32, reflect.Uint64:
+			return func(id int64) error { value.SetUint(uint64(id)); return nil }, nil
+		}
+	}
+
+	return nil, fmt.Errorf("dao: unsupported generated column %s type %T", fields[0], pointers[0])
 }
 
 func (s *DAO) getFields(entity scanner.Tabler, operation string) ([]string, []interface{}) {
@@ -75,64 +187,56 @@ func (s *DAO) getFields(entity scanner.Tabler, operation string) ([]string, []in
 	return fieldsName, fieldsPointers
 }
 
-func (s *DAO) Upsert(ctx context.Context, entities []scanner.Tabler, opts ...interface{}) error {
-	if len(entities) < 1 {
-		return fmt.Errorf("SQL adapter: zero entities passed")
-	}
-	createFields, _ := s.getFields(entities[0], scanner.Create)
-	queryRaw := s.sqlBuilder.Insert(entities[0].TableName()).Columns(createFields...)
-
-	for i := range entities {
-		_, createFieldsPointers := s.getFields(entities[i], "create")
-		queryRaw = queryRaw.Values(createFieldsPointers...)
-	}
-
-	query, args, err := queryRaw.ToSql()
-	if err != nil {
-		return err
+// operationFields поля операции или колонки опции WithColumns вместе с указателями сущности
+func (s *DAO) operationFields(entity scanner.Tabler, operation string, o *options) ([]string, []interface{}, error) {
+	if len(o.columns) < 1 {
+		fields, pointers := s.getFields(entity, operation)
+		return fields, pointers, nil
 	}
 
-	conflictFields, _ := s.getFields(entities[0], scanner.Conflict)
-	if len(conflictFields) > 0 {
-		query = query + " ON CONFLICT (%s)"
-		query = fmt.Sprintf(query, strings.Join(conflictFields, ","))
-		query = query + " DO UPDATE SET"
+	allFields, allPointers := s.getFields(entity, scanner.AllFields)
+	index := make(map[string]int, len(allFields))
+	for i := range allFields {
+		index[allFields[i]] = i
 	}
-	upsertFields, _ := s.getFields(entities[0], scanner.Upsert)
-	for _, field := range upsertFields {
-		query += fmt.Sprintf(" %s = excluded.%s,", field, field)
-	}
-	if len(upsertFields) > 0 {
-		query = query[0 : len(query)-1]
+	fields := make([]string, 0, len(o.columns))
+	pointers := make([]interface{}, 0, len(o.columns))
+	for _, column := range o.columns {
+		i, ok := index[column]
+		if !ok {
+			return nil, nil, &UnknownColumnError{Table: entity.TableName(), Column: column}
+		}
+		fields = append(fields, allFields[i])
+		pointers = append(pointers, allPointers[i])
 	}
 
-	if tx := getTransaction(opts); tx != nil {
-		_, err = tx.ExecContext(ctx, query, args...)
-	} else {
-		_, err = s.db.ExecContext(ctx, query, args...)
-	}
+	return fields, pointers, nil
+}
 
-	return err
+// selectQuery описание запроса выборки
+type selectQuery struct {
+	// tables основная таблица и присоединенные таблицы
+	tables []string
+	// joins предложения JOIN из joinClauses
+	joins  []string
+	fields []string
+	// lock окончание запроса из lockClause
+	lock string
 }
 
-func (s *DAO) buildSelect(tableName string, condition utils.Condition, fields ...string) (string, []interface{}, error) {
-	if condition.ForUpdate {
-		temp := []string{"FOR UPDATE"}
-		temp = append(temp, fields...)
-		fields = temp
+// selectBuilder построитель запроса выборки по условию
+func (s *DAO) selectBuilder(q selectQuery, condition utils.Condition) (sq.SelectBuilder, error) {
+	queryRaw := s.sqlBuilder.Select(q.fields...).From(s.dbConf.QualifiedName(q.tables[0]))
+	for _, join := range q.joins {
+		queryRaw = queryRaw.JoinClause(join)
 	}
-	queryRaw := s.sqlBuilder.Select(fields...).From(tableName)
 
-	if condition.Equal != nil {
-		for field, args := range condition.Equal {
-			queryRaw = queryRaw.Where(sq.Eq{field: args})
-		}
+	predicates, err := s.conditionPredicates(q.tables, condition)
+	if err != nil {
+		return queryRaw, err
 	}
-
-	if condition.NotEqual != nil {
-		for field, args := range condition.NotEqual {
-			queryRaw = queryRaw.Where(sq.NotEq{field: args})
-		}
+	for _, predicate := range predicates {
+		queryRaw = queryRaw.Where(predicate)
 	}
 
 	if condition.Order != nil {
@@ -141,38 +245,58 @@ func (s *DAO) buildSelect(tableName string, condition utils.Condition, fields ..
 			if order.Asc {
 				direction = "ASC"
 			}
-			queryRaw = queryRaw.OrderBy(fmt.Sprintf("%s %s", order.Field, direction))
+			column, err := s.column(q.tables, order.Field)
+			if err != nil {
+				return queryRaw, err
+			}
+			queryRaw = queryRaw.OrderBy(fmt.Sprintf("%s %s", column, direction))
 		}
 	}
 
 	if condition.LimitOffset != nil {
 		if condition.LimitOffset.Limit > 0 {
-			queryRaw.Limit(uint64(condition.LimitOffset.Limit))
+			queryRaw = queryRaw.Limit(uint64(condition.LimitOffset.Limit))
 		}
 		if condition.LimitOffset.Offset > 0 {
-			queryRaw.Offset(uint64(condition.LimitOffset.Offset))
+			queryRaw = queryRaw.Offset(uint64(condition.LimitOffset.Offset))
 		}
 	}
 
-	return queryRaw.ToSql()
+	if q.lock != "" {
+		queryRaw = queryRaw.Suffix(q.lock)
+	}
+
+	return queryRaw, nil
 }
 
-func (s *DAO) GetCount(ctx context.Context, entity scanner.Tabler, condition utils.Condition, opts ...interface{}) (uint64, error) {
-	query, args, err := s.buildSelect(entity.TableName(), condition, "COUNT(*)")
+// GetCount количество строк по условию, блокировка строк не применяется
+func (s *DAO) GetCount(ctx context.Context, entity scanner.Tabler, condition utils.Condition, opts ...Option) (uint64, error) {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	q := selectQuery{tables: []string{entity.TableName()}, fields: []string{"COUNT(*)"}}
+	if len(o.joins) > 0 {
+		var err error
+		q.tables, q.joins, err = s.joinQuery(entity.TableName(), o.joins)
+		if err != nil {
+			return 0, err
+		}
+	}
+	condition = s.scopeDeleted(entity.TableName(), condition, o)
+	queryRaw, err := s.selectBuilder(q, condition)
 	if err != nil {
 		return 0, err
 	}
-
-	var rows *sqlx.Rows
-	if tx := getTransaction(opts); tx != nil {
-		rows, err = tx.QueryxContext(ctx, query, args...)
-	} else {
-		rows, err = s.db.QueryxContext(ctx, query, args...)
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return 0, err
 	}
 
+	rows, err := s.executor(ctx, o).QueryxContext(ctx, query, args...)
 	if err != nil {
 		return 0, err
 	}
+	defer rows.Close()
 
 	var count uint64
 	// iterate over each row
@@ -188,42 +312,73 @@ func (s *DAO) GetCount(ctx context.Context, entity scanner.Tabler, condition uti
 	return count, err
 }
 
-func (s *DAO) List(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, opts ...interface{}) error {
-	fields, _ := s.getFields(table, scanner.AllFields)
-	query, args, err := s.buildSelect(table.TableName(), condition, fields...)
+func (s *DAO) List(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	q := selectQuery{tables: []string{table.TableName()}}
+	var err error
+	if len(o.joins) > 0 {
+		q.tables, q.joins, err = s.joinQuery(table.TableName(), o.joins)
+		if err != nil {
+			return err
+		}
+		q.fields, err = s.joinFields(q.tables, o)
+	} else {
+		q.fields, _, err = s.operationFields(table, scanner.AllFields, o)
+		q.fields = s.quoteColumns(q.fields)
+	}
+	if err != nil {
+		return err
+	}
+	q.lock, err = s.lockClause(ctx, o, condition.ForUpdate)
+	if err != nil {
+		return err
+	}
+	condition = s.scopeDeleted(table.TableName(), condition, o)
+	queryRaw, err := s.selectBuilder(q, condition)
+	if err != nil {
+		return err
+	}
+	query, args, err := queryRaw.ToSql()
 	if err != nil {
 		return err
 	}
 
-	if tx := getTransaction(opts); tx != nil {
-		err = tx.SelectContext(ctx, dest, query, args...)
-	} else {
-		err = s.db.SelectContext(ctx, dest, query, args...)
+	err = s.executor(ctx, o).SelectContext(ctx, dest, query, args...)
+	if err != nil {
+		return err
 	}
 
-	return err
+	return s.preload(ctx, dest, table, o)
 }
 
-func (s *DAO) Update(ctx context.Context, entity scanner.Tabler, condition utils.Condition, operation string, opts ...interface{}) error {
-	ent := entity
-	updateFields, updateFieldsPointers := s.getFields(entity, operation)
+func (s *DAO) Update(ctx context.Context, entity scanner.Tabler, condition utils.Condition, operation string, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
 
-	updateRaw := s.sqlBuilder.Update(ent.TableName())
+	ent := entity
+	updateFields, updateFieldsPointers, err := s.operationFields(entity, operation, o)
+	if err != nil {
+		return err
+	}
 
-	if condition.Equal != nil {
-		for field, args := range condition.Equal {
-			updateRaw = updateRaw.Where(sq.Eq{field: args})
-		}
+	if condition.IsEmpty() && !o.force {
+		return ErrEmptyCondition
 	}
 
-	if condition.NotEqual != nil {
-		for field, args := range condition.NotEqual {
-			updateRaw = updateRaw.Where(sq.NotEq{field: args})
-		}
+	updateRaw := s.sqlBuilder.Update(s.dbConf.QualifiedName(ent.TableName()))
+
+	predicates, err := s.conditionPredicates([]string{ent.TableName()}, condition)
+	if err != nil {
+		return err
+	}
+	for _, predicate := range predicates {
+		updateRaw = updateRaw.Where(predicate)
 	}
 
 	for i := range updateFields {
-		updateRaw = updateRaw.Set(updateFields[i], updateFieldsPointers[i])
+		updateRaw = updateRaw.Set(s.quoteIdentifier(updateFields[i]), updateFieldsPointers[i])
 	}
 
 	query, args, err := updateRaw.ToSql()
@@ -231,12 +386,7 @@ func (s *DAO) Update(ctx context.Context, entity scanner.Tabler, condition utils
 		return err
 	}
 
-	var res sql.Result
-	if tx := getTransaction(opts); tx != nil {
-		res, err = tx.ExecContext(ctx, query, args...)
-	} else {
-		res, err = s.db.ExecContext(ctx, query, args...)
-	}
+	res, err := s.executor(ctx, o).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -245,12 +395,43 @@ func (s *DAO) Update(ctx context.Context, entity scanner.Tabler, condition utils
 	return err
 }
 
-func getTransaction(opts ...interface{}) *sqlx.Tx {
-	for _, opt := range opts {
-		switch opt.(type) {
-		case *sqlx.Tx:
-			return opt.(*sqlx.Tx)
+// conditionPredicates условия Equal и NotEqual в порядке имен полей,
+// затем выражения Where в порядке объявления, все колонки проверяются
+// по зарегистрированным полям таблиц запроса
+func (s *DAO) conditionPredicates(tables []string, condition utils.Condition) ([]sq.Sqlizer, error) {
+	var predicates []sq.Sqlizer
+	for _, field := range sortedKeys(condition.Equal) {
+		column, err := s.column(tables, field)
+		if err != nil {
+			return nil, err
 		}
+		predicates = append(predicates, sq.Eq{column: condition.Equal[field]})
 	}
-	return nil
+	for _, field := range sortedKeys(condition.NotEqual) {
+		column, err := s.column(tables, field)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, sq.NotEq{column: condition.NotEqual[field]})
+	}
+	for i := range condition.Where {
+		predicate, err := s.exprPredicate(tables, condition.Where[i])
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	return predicates, nil
+}
+
+// sortedKeys ключи карты условий в отсортированном порядке
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
@@ -0,0 +1,168 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// SoftDeleteField поле отметки мягкого удаления
+const SoftDeleteField = "deleted_at"
+
+var (
+	// ErrEmptyCondition ошибка изменения всех строк таблицы без явного разрешения
+	ErrEmptyCondition = errors.New("dao: empty condition, pass dao.Force() to affect all rows")
+	// ErrSoftDeleteUnsupported ошибка мягкого удаления в таблице без поля deleted_at
+	ErrSoftDeleteUnsupported = errors.New("dao: table has no deleted_at field")
+)
+
+// scopeDeleted добавление условия на deleted_at для таблиц с мягким удалением,
+// условие не добавляется, если поле уже указано в Equal, NotEqual или Where condition
+func (s *DAO) scopeDeleted(tableName string, condition utils.Condition, o *options) utils.Condition {
+	scope := o.deleted
+	if scope == scopeWithDeleted || !s.softDeletable(tableName) {
+		return condition
+	}
+	if referencesDeleted(tableName, condition) {
+		return condition
+	}
+
+	if scope == scopeOnlyDeleted {
+		condition.NotEqual = copyWith(condition.NotEqual, SoftDeleteField, nil)
+	} else {
+		condition.Equal = copyWith(condition.Equal, SoftDeleteField, nil)
+	}
+
+	return condition
+}
+
+// referencesDeleted наличие в условии поля deleted_at основной таблицы,
+// в том числе в виде table.deleted_at и во вложенных выражениях
+func referencesDeleted(tableName string, condition utils.Condition) bool {
+	isDeleted := func(field string) bool {
+		return field == SoftDeleteField || field == tableName+"."+SoftDeleteField
+	}
+	for field := range condition.Equal {
+		if isDeleted(field) {
+			return true
+		}
+	}
+	for field := range condition.NotEqual {
+		if isDeleted(field) {
+			return true
+		}
+	}
+	var walk func(exprs []utils.Expr) bool
+	walk = func(exprs []utils.Expr) bool {
+		for i := range exprs {
+			if isDeleted(exprs[i].Field) || walk(exprs[i].Exprs) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return walk(condition.Where)
+}
+
+// copyWith копия карты условий с добавленным значением
+func copyWith(m map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	copied[key] = value
+
+	return copied
+}
+
+// softDeletable наличие в таблице поля deleted_at
+func (s *DAO) softDeletable(tableName string) bool {
+	_, ok := s.scanner.Table(tableName).FieldsMap[SoftDeleteField]
+
+	return ok
+}
+
+// Delete удаление строк таблицы по условию
+func (s *DAO) Delete(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	if condition.IsEmpty() && !o.force {
+		return ErrEmptyCondition
+	}
+
+	deleteRaw := s.sqlBuilder.Delete(s.dbConf.QualifiedName(table.TableName()))
+	predicates, err := s.conditionPredicates([]string{table.TableName()}, condition)
+	if err != nil {
+		return err
+	}
+	for _, predicate := range predicates {
+		deleteRaw = deleteRaw.Where(predicate)
+	}
+
+	query, args, err := deleteRaw.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.executor(ctx, o).ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// SoftDelete мягкое удаление: заполнение deleted_at у еще не удаленных строк
+func (s *DAO) SoftDelete(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error {
+	return s.setDeletedAt(ctx, table, condition, time.Now(), opts...)
+}
+
+// Restore восстановление мягко удаленных строк: очистка deleted_at
+func (s *DAO) Restore(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) error {
+	return s.setDeletedAt(ctx, table, condition, nil, opts...)
+}
+
+// setDeletedAt установка deleted_at у строк, которые еще не находятся в нужном состоянии
+func (s *DAO) setDeletedAt(ctx context.Context, table scanner.Tabler, condition utils.Condition, value interface{}, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	if !s.softDeletable(table.TableName()) {
+		return fmt.Errorf("%w: %s", ErrSoftDeleteUnsupported, table.TableName())
+	}
+	if condition.IsEmpty() && !o.force {
+		return ErrEmptyCondition
+	}
+
+	updateRaw := s.sqlBuilder.Update(s.dbConf.QualifiedName(table.TableName())).Set(s.quoteIdentifier(SoftDeleteField), value)
+	predicates, err := s.conditionPredicates([]string{table.TableName()}, condition)
+	if err != nil {
+		return err
+	}
+	for _, predicate := range predicates {
+		updateRaw = updateRaw.Where(predicate)
+	}
+	if value == nil {
+		updateRaw = updateRaw.Where(sq.NotEq{s.quoteIdentifier(SoftDeleteField): nil})
+	} else {
+		updateRaw = updateRaw.Where(sq.Eq{s.quoteIdentifier(SoftDeleteField): nil})
+	}
+
+	query, args, err := updateRaw.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := s.executor(ctx, o).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	_, err = res.RowsAffected()
+
+	return err
+}
@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// exprPredicate преобразование выражения условия в выражение squirrel,
+// поля выражения проверяются по зарегистрированным колонкам таблиц запроса
+func (s *DAO) exprPredicate(tables []string, expr utils.Expr) (sq.Sqlizer, error) {
+	return s.predicate(expr, func(name string) (string, error) {
+		return s.column(tables, name)
+	})
+}
+
+// predicate преобразование выражения в выражение squirrel, resolve
+// возвращает выражение SQL для поля выражения
+func (s *DAO) predicate(expr utils.Expr, resolve func(name string) (string, error)) (sq.Sqlizer, error) {
+	switch expr.Op {
+	case utils.OpAnd, utils.OpOr:
+		if len(expr.Exprs) < 1 {
+			return nil, fmt.Errorf("dao: %s requires at least one expression", expr.Op)
+		}
+		predicates := make([]sq.Sqlizer, 0, len(expr.Exprs))
+		for i := range expr.Exprs {
+			predicate, err := s.predicate(expr.Exprs[i], resolve)
+			if err != nil {
+				return nil, err
+			}
+			predicates = append(predicates, predicate)
+		}
+		if expr.Op == utils.OpOr {
+			return sq.Or(predicates), nil
+		}
+		return sq.And(predicates), nil
+	case utils.OpNot:
+		if len(expr.Exprs) != 1 {
+			return nil, fmt.Errorf("dao: NOT requires exactly one expression")
+		}
+		predicate, err := s.predicate(expr.Exprs[0], resolve)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr("NOT (?)", predicate), nil
+	}
+
+	if expr.Field == "" {
+		return nil, fmt.Errorf("dao: %s requires a field", expr.Op)
+	}
+	column, err := resolve(expr.Field)
+	if err != nil {
+		return nil, err
+	}
+	switch expr.Op {
+	case utils.OpIsNull:
+		return sq.Eq{column: nil}, nil
+	case utils.OpIsNotNull:
+		return sq.NotEq{column: nil}, nil
+	case utils.OpBetween:
+		if len(expr.Args) != 2 {
+			return nil, fmt.Errorf("dao: BETWEEN %s requires two arguments", expr.Field)
+		}
+		return sq.Expr(fmt.Sprintf("%s BETWEEN ? AND ?", column), expr.Args[0], expr.Args[1]), nil
+	}
+
+	if len(expr.Args) != 1 {
+		return nil, fmt.Errorf("dao: %s %s requires one argument", expr.Field, expr.Op)
+	}
+	value := expr.Args[0]
+	switch expr.Op {
+	case utils.OpEq:
+		return sq.Eq{column: value}, nil
+	case utils.OpNotEq:
+		return sq.NotEq{column: value}, nil
+	case utils.OpGt:
+		return sq.Gt{column: value}, nil
+	case utils.OpGte:
+		return sq.GtOrEq{column: value}, nil
+	case utils.OpLt:
+		return sq.Lt{column: value}, nil
+	case utils.OpLte:
+		return sq.LtOrEq{column: value}, nil
+	case utils.OpLike:
+		return sq.Like{column: value}, nil
+	case utils.OpILike:
+		if s.dbConf.Driver == "postgres" {
+			return sq.ILike{column: value}, nil
+		}
+		return sq.Expr(fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column), value), nil
+	case utils.OpIn, utils.OpNotIn:
+		if !isSlice(value) {
+			return nil, fmt.Errorf("dao: %s %s requires a slice argument", expr.Field, expr.Op)
+		}
+		if expr.Op == utils.OpNotIn {
+			return sq.NotEq{column: value}, nil
+		}
+		return sq.Eq{column: value}, nil
+	}
+
+	return nil, fmt.Errorf("dao: unsupported operator %q", expr.Op)
+}
+
+// isSlice значение является срезом или массивом
+func isSlice(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+var (
+	// ErrNotFound ошибка отсутствия строки, errors.Is(err, sql.ErrNoRows) также выполняется
+	ErrNotFound = fmt.Errorf("dao: not found: %w", sql.ErrNoRows)
+	// ErrMultipleRows ошибка выборки нескольких строк там, где ожидается одна
+	ErrMultipleRows = errors.New("dao: multiple rows found")
+)
+
+// Get выборка одной строки в dest через FieldsPointers,
+// при отсутствии строки возвращается ErrNotFound,
+// с опцией Unique при нескольких строках возвращается ErrMultipleRows и dest не изменяется
+func (s *DAO) Get(ctx context.Context, dest scanner.Tabler, condition utils.Condition, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	fields, pointers, err := s.operationFields(dest, scanner.AllFields, o)
+	if err != nil {
+		return err
+	}
+	lock, err := s.lockClause(ctx, o, condition.ForUpdate)
+	if err != nil {
+		return err
+	}
+	condition = s.scopeDeleted(dest.TableName(), condition, o)
+
+	limit := uint64(1)
+	unique := o.unique
+	if unique {
+		limit = 2
+	}
+	queryRaw, err := s.selectBuilder(selectQuery{tables: []string{dest.TableName()}, fields: s.quoteColumns(fields), lock: lock}, condition)
+	if err != nil {
+		return err
+	}
+	query, args, err := queryRaw.Limit(limit).ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.executor(ctx, o).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return ErrNotFound
+	}
+	if !unique {
+		if err = rows.Scan(pointers...); err != nil {
+			return err
+		}
+		return rows.Err()
+	}
+
+	// строка сканируется во временную сущность, чтобы при ErrMultipleRows dest не изменялся
+	row := reflect.New(reflect.TypeOf(dest).Elem()).Interface().(scanner.Tabler)
+	_, rowPointers, err := s.operationFields(row, scanner.AllFields, o)
+	if err != nil {
+		return err
+	}
+	if err = rows.Scan(rowPointers...); err != nil {
+		return err
+	}
+	if rows.Next() {
+		return ErrMultipleRows
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for i := range pointers {
+		reflect.ValueOf(pointers[i]).Elem().Set(reflect.ValueOf(rowPointers[i]).Elem())
+	}
+
+	return nil
+}
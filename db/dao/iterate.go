@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+// errStopIteration остановка обхода строк потребителем итератора
+var errStopIteration = errors.New("dao: iteration stopped")
+
+// Iterate построчный обход строк по условию без загрузки всей выборки в память:
+// каждая строка сканируется в новую сущность типа table и передается в fn,
+// ошибка fn или отмена контекста прекращают обход и возвращаются.
+// Соединение занято до окончания обхода, запросы внутри fn следует выполнять
+// в другом соединении
+func (s *DAO) Iterate(ctx context.Context, table scanner.Tabler, condition utils.Condition, fn func(row scanner.Tabler) error, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	fields, _, err := s.operationFields(table, scanner.AllFields, o)
+	if err != nil {
+		return err
+	}
+	lock, err := s.lockClause(ctx, o, condition.ForUpdate)
+	if err != nil {
+		return err
+	}
+	condition = s.scopeDeleted(table.TableName(), condition, o)
+	queryRaw, err := s.selectBuilder(selectQuery{tables: []string{table.TableName()}, fields: s.quoteColumns(fields), lock: lock}, condition)
+	if err != nil {
+		return err
+	}
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.executor(ctx, o).QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	entityType := reflect.TypeOf(table).Elem()
+	for rows.Next() {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		row := reflect.New(entityType).Interface().(scanner.Tabler)
+		_, pointers, err := s.operationFields(row, scanner.AllFields, o)
+		if err != nil {
+			return err
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if err = fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
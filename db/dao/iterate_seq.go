@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+// Rows итератор строк по условию для range, аналог Iterate:
+//
+//	for row, err := range d.Rows(ctx, &models.TestDTO{}, condition) {
+//		if err != nil {
+//			return err
+//		}
+//	}
+//
+// ошибка запроса передается последней парой, выход из цикла закрывает строки
+func (s *DAO) Rows(ctx context.Context, table scanner.Tabler, condition utils.Condition, opts ...Option) iter.Seq2[scanner.Tabler, error] {
+	return func(yield func(scanner.Tabler, error) bool) {
+		err := s.Iterate(ctx, table, condition, func(row scanner.Tabler) error {
+			if !yield(row, nil) {
+				return errStopIteration
+			}
+			return nil
+		}, opts...)
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+)
+
+// JoinType тип соединения таблиц
+type JoinType string
+
+const (
+	JoinInner JoinType = "INNER JOIN"
+	JoinLeft  JoinType = "LEFT JOIN"
+)
+
+// ErrJoinCondition ошибка вывода условия соединения из тегов db_fk
+var ErrJoinCondition = errors.New("dao: cannot infer join condition")
+
+// Join соединение с зарегистрированной таблицей, каждая таблица
+// присоединяется к запросу не более одного раза
+type Join struct {
+	Type  JoinType
+	Table string
+	// On колонки условия соединения в виде table.column, по умолчанию
+	// выводятся из тегов db_fk между Table и таблицами, уже участвующими в запросе
+	On [2]string
+}
+
+// WithJoin соединение таблиц в List и GetCount. Колонки присоединенных таблиц
+// в условиях, сортировке и WithColumns указываются как table.column, без WithColumns
+// выбираются все колонки всех таблиц с псевдонимами table.column, что соответствует
+// структуре проекции с вложенными моделями, например `db:"users"`
+func WithJoin(joins ...Join) Option {
+	return func(o *options) {
+		o.joins = append(o.joins, joins...)
+	}
+}
+
+// joinQuery таблицы и предложения JOIN запроса с основной таблицей base
+func (s *DAO) joinQuery(base string, joins []Join) ([]string, []string, error) {
+	tables := []string{base}
+	clauses := make([]string, 0, len(joins))
+	for _, join := range joins {
+		if _, ok := s.scanner.Tables()[join.Table]; !ok {
+			return nil, nil, fmt.Errorf("dao: join: table %s is not registered", join.Table)
+		}
+		if contains(tables, join.Table) {
+			return nil, nil, fmt.Errorf("dao: join: table %s is already in the query", join.Table)
+		}
+
+		left, right := join.On[0], join.On[1]
+		if left == "" || right == "" {
+			var err error
+			left, right, err = s.inferJoin(tables, join.Table)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		tables = append(tables, join.Table)
+		leftColumn, err := s.column(tables, left)
+		if err != nil {
+			return nil, nil, err
+		}
+		rightColumn, err := s.column(tables, right)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		joinType := join.Type
+		if joinType == "" {
+			joinType = JoinInner
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s ON %s = %s", joinType, s.dbConf.QualifiedName(join.Table), leftColumn, rightColumn))
+	}
+
+	return tables, clauses, nil
+}
+
+// inferJoin условие соединения table с таблицами запроса по тегам db_fk:
+// поле table ссылается на таблицу запроса или поле таблицы запроса ссылается на table
+func (s *DAO) inferJoin(tables []string, table string) (string, string, error) {
+	var candidates [][2]string
+	for _, field := range s.scanner.Table(table).Fields {
+		if fk := field.ForeignKey; fk != nil && contains(tables, fk.Table) {
+			candidates = append(candidates, [2]string{table + "." + field.Name, fk.Table + "." + fk.Column})
+		}
+	}
+	for _, name := range tables {
+		for _, field := range s.scanner.Table(name).Fields {
+			if fk := field.ForeignKey; fk != nil && fk.Table == table {
+				candidates = append(candidates, [2]string{name + "." + field.Name, table + "." + fk.Column})
+			}
+		}
+	}
+	if len(candidates) != 1 {
+		return "", "", fmt.Errorf("%w: %s has %d foreign keys to the query tables, set Join.On", ErrJoinCondition, table, len(candidates))
+	}
+
+	return candidates[0][0], candidates[0][1], nil
+}
+
+// joinFields выбираемые колонки запроса с соединениями с псевдонимами,
+// совпадающими с указанием колонки
+func (s *DAO) joinFields(tables []string, o *options) ([]string, error) {
+	var fields []string
+	if len(o.columns) > 0 {
+		for _, ref := range o.columns {
+			column, err := s.column(tables, ref)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, column+" AS "+s.quoteIdentifier(ref))
+		}
+		return fields, nil
+	}
+
+	for _, table := range tables {
+		for _, field := range s.scanner.Table(table).Fields {
+			ref := table + "." + field.Name
+			column, err := s.column(tables, ref)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, column+" AS "+s.quoteIdentifier(ref))
+		}
+	}
+
+	return fields, nil
+}
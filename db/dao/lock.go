@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+)
+
+// LockMode режим блокировки выбранных строк
+type LockMode string
+
+const (
+	LockForUpdate      LockMode = "FOR UPDATE"
+	LockForNoKeyUpdate LockMode = "FOR NO KEY UPDATE"
+	LockForShare       LockMode = "FOR SHARE"
+)
+
+// LockWait поведение блокировки при уже заблокированных строках
+type LockWait string
+
+const (
+	// LockNoWait ошибка вместо ожидания блокировки
+	LockNoWait LockWait = "NOWAIT"
+	// LockSkipLocked пропуск заблокированных строк
+	LockSkipLocked LockWait = "SKIP LOCKED"
+)
+
+// ErrLockWithoutTx ошибка блокировки строк вне транзакции, блокировка снялась бы сразу после запроса
+var ErrLockWithoutTx = errors.New("dao: row lock requires a transaction")
+
+// lockClause окончание запроса выборки с блокировкой строк для диалекта:
+// mysql не поддерживает FOR NO KEY UPDATE и получает FOR UPDATE,
+// sqlite блокирует всю базу на запись, поэтому блокировка пропускается с предупреждением
+func (s *DAO) lockClause(ctx context.Context, o *options, forUpdate bool) (string, error) {
+	mode := o.lock
+	if mode == "" && forUpdate {
+		mode = LockForUpdate
+	}
+	if mode == "" {
+		return "", nil
+	}
+	if o.tx == nil && TxFromContext(ctx) == nil {
+		return "", ErrLockWithoutTx
+	}
+
+	switch s.dbConf.Driver {
+	case DriverPostgres:
+	case DriverMysql:
+		if mode == LockForNoKeyUpdate {
+			mode = LockForUpdate
+		}
+	default:
+		s.lockWarning.Do(
+			func() {
+				s.logger.Warn("row locks are not supported by driver, lock ignored", zap.String("driver", s.dbConf.Driver), zap.String("lock", string(mode)))
+			},
+		)
+		return "", nil
+	}
+
+	if o.lockWait != "" {
+		return string(mode) + " " + string(o.lockWait), nil
+	}
+
+	return string(mode), nil
+}
@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Option опция вызова DAO
+type Option func(*options)
+
+// deletedScope область выборки относительно мягко удаленных строк
+type deletedScope int
+
+const (
+	scopeNotDeleted deletedScope = iota
+	scopeWithDeleted
+	scopeOnlyDeleted
+)
+
+// options опции вызова DAO
+type options struct {
+	tx             *sqlx.Tx
+	timeout        time.Duration
+	lock           LockMode
+	lockWait       LockWait
+	columns        []string
+	joins          []Join
+	preload        []string
+	deleted        deletedScope
+	comment        string
+	force          bool
+	unique         bool
+	doNothing      bool
+	separateChunks bool
+	upsertResult   *UpsertResult
+}
+
+// newOptions применение опций
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	return o
+}
+
+// prepare применение опций и ограничения времени выполнения вызова
+func (s *DAO) prepare(ctx context.Context, opts []Option) (context.Context, *options, context.CancelFunc) {
+	o := newOptions(opts...)
+	if o.timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, o.timeout)
+		return ctx, o, cancel
+	}
+
+	return ctx, o, func() {}
+}
+
+// WithTx выполнение вызова в транзакции tx, приоритетнее транзакции контекста
+func WithTx(tx *sqlx.Tx) Option {
+	return func(o *options) {
+		o.tx = tx
+	}
+}
+
+// WithTimeout ограничение времени выполнения вызова
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithLock блокировка строк, выбранных List и Get, в транзакции,
+// wait задает поведение при уже заблокированных строках: NOWAIT или SKIP LOCKED
+func WithLock(mode LockMode, wait ...LockWait) Option {
+	return func(o *options) {
+		o.lock = mode
+		o.lockWait = ""
+		if len(wait) > 0 {
+			o.lockWait = wait[0]
+		}
+	}
+}
+
+// WithColumns ограничение колонок вызова вместо полей операции:
+// выбираемые колонки List и Get, изменяемые колонки Update, вставляемые колонки Create и Upsert
+func WithColumns(columns ...string) Option {
+	return func(o *options) {
+		o.columns = columns
+	}
+}
+
+// WithDeleted выборка вместе с мягко удаленными строками
+func WithDeleted() Option {
+	return func(o *options) {
+		o.deleted = scopeWithDeleted
+	}
+}
+
+// OnlyDeleted выборка только мягко удаленных строк
+func OnlyDeleted() Option {
+	return func(o *options) {
+		o.deleted = scopeOnlyDeleted
+	}
+}
+
+// WithComment комментарий в начале запроса для поиска в журналах и статистике базы данных
+func WithComment(tag string) Option {
+	return func(o *options) {
+		o.comment = strings.ReplaceAll(tag, "*/", "* /")
+	}
+}
+
+// Force разрешение Update и Delete без условий, то есть для всех строк таблицы
+func Force() Option {
+	return func(o *options) {
+		o.force = true
+	}
+}
+
+// Unique проверка в Get, что условию соответствует ровно одна строка
+func Unique() Option {
+	return func(o *options) {
+		o.unique = true
+	}
+}
+
+// DoNothing пропуск конфликтующих строк в Upsert вместо обновления
+func DoNothing() Option {
+	return func(o *options) {
+		o.doNothing = true
+	}
+}
+
+// SeparateChunks выполнение пачек CreateMany и Upsert без общей транзакции:
+// ошибка пачки не отменяет остальные, ошибки возвращаются в BatchError.
+// Внутри транзакции пачки выполняются в отдельных точках сохранения
+func SeparateChunks() Option {
+	return func(o *options) {
+		o.separateChunks = true
+	}
+}
+
+// WithUpsertResult заполнение result количеством строк, обработанных Upsert
+func WithUpsertResult(result *UpsertResult) Option {
+	return func(o *options) {
+		o.upsertResult = result
+	}
+}
+
+// OptionsFrom преобразование нетипизированных опций прежних вызовов: Option, *sqlx.Tx
+// и *UpsertResult, nil пропускается. Значение другого типа приводит к панике, чтобы вызов
+// не выполнился молча вне транзакции или без результата.
+//
+// Deprecated: передавайте опции WithTx, WithUpsertResult и другие напрямую.
+func OptionsFrom(legacy ...interface{}) []Option {
+	opts := make([]Option, 0, len(legacy))
+	for _, opt := range legacy {
+		switch opt := opt.(type) {
+		case nil:
+		case Option:
+			opts = append(opts, opt)
+		case *sqlx.Tx:
+			opts = append(opts, WithTx(opt))
+		case *UpsertResult:
+			opts = append(opts, WithUpsertResult(opt))
+		default:
+			panic(fmt.Sprintf("dao: unsupported legacy option %T", opt))
+		}
+	}
+
+	return opts
+}
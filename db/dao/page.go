@@ -0,0 +1,287 @@
+package dao
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ErrInvalidCursor ошибка курсора с неверной подписью, форматом или сортировкой
+var ErrInvalidCursor = errors.New("dao: invalid cursor")
+
+// Cursors курсоры соседних страниц, пустой курсор означает отсутствие страницы
+type Cursors struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// cursor содержимое курсора: направление, сортировка, хеш условия выборки
+// и значения полей сортировки граничной строки
+type cursor struct {
+	Backward bool              `json:"b,omitempty"`
+	Order    string            `json:"o"`
+	Filter   string            `json:"f"`
+	Values   []json.RawMessage `json:"v"`
+}
+
+// cursorKey ключ подписи курсоров из конфигурации или случайный
+func cursorKey(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("dao: cursor key: %s", err))
+	}
+
+	return key
+}
+
+// Page выборка страницы размера size по ключу (keyset) в dest: указатель на срез сущностей table.
+// Страница следует за строкой курсора в порядке Order условия, к которому добавляется
+// первичный ключ, пустой курсор означает первую страницу. Колонки сортировки должны быть
+// NOT NULL и выбираться в dest, LimitOffset условия не применяется.
+// Курсоры подписываются HMAC ключом utils.DB.CursorSecret и действительны
+// только для той же сортировки и того же условия выборки
+func (s *DAO) Page(ctx context.Context, dest interface{}, table scanner.Tabler, condition utils.Condition, token string, size uint64, opts ...Option) (Cursors, error) {
+	if size == 0 {
+		return Cursors{}, fmt.Errorf("dao: page size must be positive")
+	}
+	if s.dbConf.CursorSecret == "" {
+		s.cursorWarning.Do(
+			func() {
+				s.logger.Warn("cursor secret is not configured, cursors are signed with a random key and expire on restart")
+			},
+		)
+	}
+	meta := s.scanner.Table(table.TableName())
+	order, fields, err := s.pageOrder(meta, condition.Order)
+	if err != nil {
+		return Cursors{}, err
+	}
+	signature := cursor{Order: orderSignature(order)}
+	if signature.Filter, err = s.filterSignature(table.TableName(), condition, newOptions(opts...)); err != nil {
+		return Cursors{}, err
+	}
+
+	var after *cursor
+	if token != "" {
+		if after, err = s.decodeCursor(token, signature); err != nil {
+			return Cursors{}, err
+		}
+		values, err := cursorValues(meta, fields, after.Values)
+		if err != nil {
+			return Cursors{}, err
+		}
+		condition.Where = append(append([]utils.Expr{}, condition.Where...), keysetExpr(order, values, after.Backward))
+	}
+	backward := after != nil && after.Backward
+
+	condition.Order = order
+	if backward {
+		condition.Order = make([]*utils.Order, len(order))
+		for i := range order {
+			condition.Order[i] = &utils.Order{Field: order[i].Field, Asc: !order[i].Asc}
+		}
+	}
+	condition.LimitOffset = &utils.LimitOffset{Limit: int64(size) + 1}
+	if err = s.List(ctx, dest, table, condition, opts...); err != nil {
+		return Cursors{}, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	more := uint64(rows.Len()) > size
+	if more {
+		rows.Set(rows.Slice(0, int(size)))
+	}
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	items := structValues(rows)
+	if len(items) == 0 {
+		return Cursors{}, nil
+	}
+
+	var cursors Cursors
+	if more || backward {
+		if cursors.Next, err = s.encodeCursor(signature, items[len(items)-1], fields); err != nil {
+			return Cursors{}, err
+		}
+	}
+	if after != nil && (!backward || more) {
+		signature.Backward = true
+		if cursors.Prev, err = s.encodeCursor(signature, items[0], fields); err != nil {
+			return Cursors{}, err
+		}
+	}
+
+	return cursors, nil
+}
+
+// pageOrder сортировка страницы с первичным ключом в конце для однозначного порядка
+func (s *DAO) pageOrder(meta scanner.Table, order []*utils.Order) ([]*utils.Order, []*scanner.Field, error) {
+	key, err := primaryKey(meta)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]*utils.Order, 0, len(order)+1)
+	fields := make([]*scanner.Field, 0, len(order)+1)
+	hasKey := false
+	for _, item := range order {
+		field, ok := meta.FieldsMap[item.Field]
+		if !ok {
+			return nil, nil, &UnknownColumnError{Table: meta.Name, Column: item.Field}
+		}
+		hasKey = hasKey || field == key
+		result = append(result, &utils.Order{Field: item.Field, Asc: item.Asc})
+		fields = append(fields, field)
+	}
+	if !hasKey {
+		result = append(result, &utils.Order{Field: key.Name, Asc: true})
+		fields = append(fields, key)
+	}
+
+	return result, fields, nil
+}
+
+// orderSignature описание сортировки для проверки, что курсор получен с той же сортировкой
+func orderSignature(order []*utils.Order) string {
+	items := make([]string, 0, len(order))
+	for _, item := range order {
+		if item.Asc {
+			items = append(items, item.Field)
+			continue
+		}
+		items = append(items, "-"+item.Field)
+	}
+
+	return strings.Join(items, ",")
+}
+
+// filterSignature хеш условия выборки страницы с учетом мягкого удаления
+// для проверки, что курсор получен с тем же условием
+func (s *DAO) filterSignature(tableName string, condition utils.Condition, o *options) (string, error) {
+	condition = s.scopeDeleted(tableName, condition, o)
+	predicates, err := s.conditionPredicates([]string{tableName}, condition)
+	if err != nil {
+		return "", err
+	}
+	query, args, err := sq.And(predicates).ToSql()
+	if err != nil {
+		return "", err
+	}
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("dao: cursor: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(query))
+	hash.Write([]byte{0})
+	hash.Write(encodedArgs)
+
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16]), nil
+}
+
+// keysetExpr условие строк после значений values в порядке order, для backward до них:
+// (f1 > v1) OR (f1 = v1 AND f2 > v2) OR ...
+func keysetExpr(order []*utils.Order, values []interface{}, backward bool) utils.Expr {
+	alternatives := make([]utils.Expr, 0, len(order))
+	for i := range order {
+		exprs := make([]utils.Expr, 0, i+1)
+		for j := 0; j < i; j++ {
+			exprs = append(exprs, utils.Eq(order[j].Field, values[j]))
+		}
+		if order[i].Asc != backward {
+			exprs = append(exprs, utils.Gt(order[i].Field, values[i]))
+		} else {
+			exprs = append(exprs, utils.Lt(order[i].Field, values[i]))
+		}
+		alternatives = append(alternatives, utils.And(exprs...))
+	}
+
+	return utils.Or(alternatives...)
+}
+
+// encodeCursor курсор со значениями полей сортировки строки item
+func (s *DAO) encodeCursor(c cursor, item reflect.Value, fields []*scanner.Field) (string, error) {
+	for _, field := range fields {
+		value, err := json.Marshal(item.Field(field.IDx).Addr().Interface())
+		if err != nil {
+			return "", fmt.Errorf("dao: cursor: %w", err)
+		}
+		c.Values = append(c.Values, value)
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("dao: cursor: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(s.signCursor(payload)), nil
+}
+
+// decodeCursor проверка подписи, сортировки и условия курсора
+func (s *DAO) decodeCursor(token string, signature cursor) (*cursor, error) {
+	encoding := base64.RawURLEncoding
+	rawPayload, rawMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := encoding.DecodeString(rawPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := encoding.DecodeString(rawMAC)
+	if err != nil || !hmac.Equal(mac, s.signCursor(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err = json.Unmarshal(payload, &c); err != nil || c.Order != signature.Order || c.Filter != signature.Filter {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// signCursor подпись содержимого курсора
+func (s *DAO) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.cursorKey)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+// cursorValues значения курсора в типах полей сущности
+func cursorValues(meta scanner.Table, fields []*scanner.Field, raw []json.RawMessage) ([]interface{}, error) {
+	if len(raw) != len(fields) {
+		return nil, ErrInvalidCursor
+	}
+	entityType := reflect.TypeOf(meta.Entity).Elem()
+	values := make([]interface{}, 0, len(fields))
+	for i, field := range fields {
+		value := reflect.New(entityType.Field(field.IDx).Type)
+		if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values = append(values, value.Elem().Interface())
+	}
+
+	return values, nil
+}
@@ -0,0 +1,342 @@
+package dao
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+// ErrUnknownRelation ошибка предзагрузки связи, не описанной тегом db_rel
+var ErrUnknownRelation = errors.New("dao: unknown relation")
+
+// Preload загрузка связей из тегов db_rel для строк, выбранных List, одним запросом
+// IN (...) на связь (для many_to_many двумя), связи указываются именами полей структуры,
+// вложенные связи через точку, например Preload("Orders", "Orders.Items")
+func Preload(relations ...string) Option {
+	return func(o *options) {
+		o.preload = append(o.preload, relations...)
+	}
+}
+
+// LoadRelations загрузка связей relations для уже выбранных сущностей dest таблицы table:
+// указатель на срез, срез или указатель на структуру
+func (s *DAO) LoadRelations(ctx context.Context, dest interface{}, table scanner.Tabler, relations []string, opts ...Option) error {
+	ctx, o, cancel := s.prepare(ctx, append(opts, Preload(relations...)))
+	defer cancel()
+
+	return s.preload(ctx, dest, table, o)
+}
+
+// preload загрузка связей o.preload в сущности dest: указатель на срез или структуру
+func (s *DAO) preload(ctx context.Context, dest interface{}, table scanner.Tabler, o *options) error {
+	if len(o.preload) == 0 {
+		return nil
+	}
+	parents := structValues(reflect.ValueOf(dest))
+	if len(parents) == 0 {
+		return nil
+	}
+
+	meta := s.scanner.Table(table.TableName())
+	var names []string
+	nested := make(map[string][]string)
+	for _, path := range o.preload {
+		name, rest, _ := strings.Cut(path, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	for _, name := range names {
+		relation, ok := meta.Relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %s.%s", ErrUnknownRelation, meta.Name, name)
+		}
+		childOpts := []Option{inherit(o), Preload(nested[name]...)}
+		var err error
+		if relation.Kind == scanner.ManyToMany {
+			err = s.preloadManyToMany(ctx, parents, meta, relation, childOpts, o)
+		} else {
+			err = s.preloadHas(ctx, parents, meta, relation, childOpts)
+		}
+		if err != nil {
+			return fmt.Errorf("dao: preload %s.%s: %w", meta.Name, name, err)
+		}
+	}
+
+	return nil
+}
+
+// inherit перенос транзакции и комментария вызова в запросы связей
+func inherit(o *options) Option {
+	return func(child *options) {
+		child.tx = o.tx
+		child.comment = o.comment
+	}
+}
+
+// preloadHas загрузка связи has_one или has_many по колонке связанной таблицы
+func (s *DAO) preloadHas(ctx context.Context, parents []reflect.Value, meta scanner.Table, relation *scanner.Relation, opts []Option) error {
+	parentKey, err := primaryKey(meta)
+	if err != nil {
+		return err
+	}
+	keys, byKey := groupByKey(parents, parentKey.IDx)
+	resetRelation(parents, relation)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	childMeta := s.scanner.Table(relation.Entity.TableName())
+	foreignKey, ok := childMeta.FieldsMap[relation.ForeignKey]
+	if !ok {
+		return &UnknownColumnError{Table: relation.Entity.TableName(), Column: relation.ForeignKey}
+	}
+	children, err := s.listIn(ctx, relation.Entity, childMeta, foreignKey.Name, keys, opts)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		for _, parent := range byKey[keyOf(child.Field(foreignKey.IDx).Interface())] {
+			assignRelation(parent, relation, child)
+		}
+	}
+
+	return nil
+}
+
+// preloadManyToMany загрузка связи many_to_many через таблицу связи
+func (s *DAO) preloadManyToMany(ctx context.Context, parents []reflect.Value, meta scanner.Table, relation *scanner.Relation, opts []Option, o *options) error {
+	parentKey, err := primaryKey(meta)
+	if err != nil {
+		return err
+	}
+	keys, byKey := groupByKey(parents, parentKey.IDx)
+	resetRelation(parents, relation)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var links [][2]interface{}
+	size := s.chunkSize(1)
+	for offset := 0; offset < len(keys); offset += size {
+		chunk := keys[offset:min(offset+size, len(keys))]
+		query, args, err := s.sqlBuilder.
+			Select(s.quoteIdentifier(relation.ForeignKey), s.quoteIdentifier(relation.References)).
+			From(s.dbConf.QualifiedName(relation.JoinTable)).
+			Where(sq.Eq{s.quoteIdentifier(relation.ForeignKey): chunk}).
+			ToSql()
+		if err != nil {
+			return err
+		}
+		rows, err := s.executor(ctx, o).QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("%s, %s", err, query)
+		}
+		for rows.Next() {
+			var link [2]interface{}
+			if err = rows.Scan(&link[0], &link[1]); err != nil {
+				rows.Close()
+				return err
+			}
+			links = append(links, link)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	childMeta := s.scanner.Table(relation.Entity.TableName())
+	childKey, err := primaryKey(childMeta)
+	if err != nil {
+		return err
+	}
+	var childKeys []interface{}
+	seen := make(map[string]bool, len(links))
+	for _, link := range links {
+		if key := keyOf(link[1]); !seen[key] {
+			seen[key] = true
+			childKeys = append(childKeys, link[1])
+		}
+	}
+	if len(childKeys) == 0 {
+		return nil
+	}
+	children, err := s.listIn(ctx, relation.Entity, childMeta, childKey.Name, childKeys, opts)
+	if err != nil {
+		return err
+	}
+	childByKey := make(map[string]reflect.Value, children.Len())
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		childByKey[keyOf(child.Field(childKey.IDx).Interface())] = child
+	}
+
+	for _, link := range links {
+		child, ok := childByKey[keyOf(link[1])]
+		if !ok {
+			continue
+		}
+		for _, parent := range byKey[keyOf(link[0])] {
+			assignRelation(parent, relation, child)
+		}
+	}
+
+	return nil
+}
+
+// listIn выборка строк связанной таблицы со значением column из keys пачками
+// по ограничению количества параметров, строки упорядочены по первичному ключу
+func (s *DAO) listIn(ctx context.Context, entity scanner.Tabler, meta scanner.Table, column string, keys []interface{}, opts []Option) (reflect.Value, error) {
+	if meta.Name == "" {
+		return reflect.Value{}, fmt.Errorf("table %s is not registered", entity.TableName())
+	}
+	sliceType := reflect.SliceOf(reflect.TypeOf(entity).Elem())
+	children := reflect.MakeSlice(sliceType, 0, len(keys))
+	var order []*utils.Order
+	if key, err := primaryKey(meta); err == nil {
+		order = []*utils.Order{{Field: key.Name, Asc: true}}
+	}
+
+	size := s.chunkSize(1)
+	for offset := 0; offset < len(keys); offset += size {
+		chunk := keys[offset:min(offset+size, len(keys))]
+		dest := reflect.New(sliceType)
+		condition := utils.Condition{Where: []utils.Expr{utils.In(column, chunk)}, Order: order}
+		if err := s.List(ctx, dest.Interface(), entity, condition, opts...); err != nil {
+			return reflect.Value{}, err
+		}
+		children = reflect.AppendSlice(children, dest.Elem())
+	}
+
+	return children, nil
+}
+
+// primaryKey поле первичного ключа: поле с db_ops:"id" или колонка id
+func primaryKey(meta scanner.Table) (*scanner.Field, error) {
+	if fields := meta.OperationFields[scanner.ID]; len(fields) > 0 {
+		return fields[0], nil
+	}
+	if field, ok := meta.FieldsMap["id"]; ok {
+		return field, nil
+	}
+
+	return nil, fmt.Errorf("table %s has no primary key", meta.Name)
+}
+
+// structValues адресуемые структуры из указателя на срез структур, срез указателей или структуру
+func structValues(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		return []reflect.Value{v}
+	}
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+
+	values := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		values = append(values, item)
+	}
+
+	return values
+}
+
+// groupByKey уникальные значения поля idx и структуры с этими значениями
+func groupByKey(values []reflect.Value, idx int) ([]interface{}, map[string][]reflect.Value) {
+	var keys []interface{}
+	byKey := make(map[string][]reflect.Value, len(values))
+	for _, value := range values {
+		key := value.Field(idx).Interface()
+		k := keyOf(key)
+		if k == "" {
+			continue
+		}
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[k] = append(byKey[k], value)
+	}
+
+	return keys, byKey
+}
+
+// keyOf строковое представление значения ключа для сопоставления строк разных таблиц,
+// для NULL пустая строка
+func keyOf(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = valuer.Value(); err != nil {
+			return ""
+		}
+	}
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(value)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// resetRelation очистка поля связи перед загрузкой, has_many и many_to_many получают пустой срез
+func resetRelation(parents []reflect.Value, relation *scanner.Relation) {
+	for _, parent := range parents {
+		field := parent.Field(relation.IDx)
+		if field.Kind() == reflect.Slice {
+			field.Set(reflect.MakeSlice(field.Type(), 0, 0))
+			continue
+		}
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
+
+// assignRelation добавление связанной строки child в поле связи parent,
+// для has_one сохраняется первая строка
+func assignRelation(parent reflect.Value, relation *scanner.Relation, child reflect.Value) {
+	field := parent.Field(relation.IDx)
+	switch field.Kind() {
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Ptr {
+			field.Set(reflect.Append(field, child.Addr()))
+			return
+		}
+		field.Set(reflect.Append(field, child))
+	case reflect.Ptr:
+		if field.IsNil() {
+			field.Set(child.Addr())
+		}
+	default:
+		if field.IsZero() {
+			field.Set(child)
+		}
+	}
+}
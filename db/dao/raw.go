@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// RawQuery запрос SQL, не выразимый построителем, выполняется при сканировании результата
+type RawQuery struct {
+	dao   *DAO
+	ctx   context.Context
+	query string
+	args  []interface{}
+	opts  []Option
+	err   error
+}
+
+// Raw запрос SQL с параметрами ?, которые заменяются на формат параметров драйвера.
+// Запрос выполняется в транзакции контекста, если она есть
+func (s *DAO) Raw(ctx context.Context, query string, args ...interface{}) *RawQuery {
+	return s.RawWith(ctx, nil, query, args...)
+}
+
+// RawWith запрос SQL как в Raw с опциями вызова WithTx, WithTimeout и WithComment,
+// опции применяются при сканировании
+func (s *DAO) RawWith(ctx context.Context, opts []Option, query string, args ...interface{}) *RawQuery {
+	query, err := s.rebind(query)
+	return &RawQuery{dao: s, ctx: ctx, query: query, args: args, opts: opts, err: err}
+}
+
+// Exec выполнение запроса SQL без выборки строк, параметры и транзакция как в Raw
+func (s *DAO) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.ExecWith(ctx, nil, query, args...)
+}
+
+// ExecWith выполнение запроса SQL как в Exec с опциями вызова WithTx, WithTimeout и WithComment
+func (s *DAO) ExecWith(ctx context.Context, opts []Option, query string, args ...interface{}) (sql.Result, error) {
+	query, err := s.rebind(query)
+	if err != nil {
+		return nil, err
+	}
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+	res, err := s.executor(ctx, o).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	return res, nil
+}
+
+// rebind замена параметров ? на формат параметров DAO, ?? обозначает знак вопроса
+func (s *DAO) rebind(query string) (string, error) {
+	if s.dbConf.Driver == DriverMysql {
+		return strings.ReplaceAll(query, "??", "?"), nil
+	}
+	rebound, err := sq.Dollar.ReplacePlaceholders(query)
+	if err != nil {
+		return "", fmt.Errorf("dao: raw: %w, %s", err, query)
+	}
+
+	return rebound, nil
+}
+
+// Scan выполнение запроса и сканирование строк в dest: зарегистрированную сущность
+// (первая строка, при отсутствии строк ErrNotFound) или указатель на срез сущностей
+// либо указателей на них. Колонки результата сопоставляются с тегами db полей сущности,
+// для колонки без поля возвращается UnknownColumnError
+func (q *RawQuery) Scan(dest interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	s := q.dao
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("dao: raw: destination must be a non-nil pointer, got %T", dest)
+	}
+
+	single, ok := dest.(scanner.Tabler)
+	var slice reflect.Value
+	var elemType reflect.Type
+	if !ok {
+		slice = target.Elem()
+		if slice.Kind() != reflect.Slice {
+			return fmt.Errorf("dao: raw: destination must be an entity or a pointer to a slice, got %T", dest)
+		}
+		elemType = slice.Type().Elem()
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if single, ok = reflect.New(elemType).Interface().(scanner.Tabler); !ok {
+			return fmt.Errorf("dao: raw: %s does not implement scanner.Tabler", elemType)
+		}
+	}
+	table := s.scanner.Table(single.TableName())
+	if table.Name == "" {
+		return fmt.Errorf("dao: raw: table %s is not registered", single.TableName())
+	}
+
+	ctx, o, cancel := s.prepare(q.ctx, q.opts)
+	defer cancel()
+	rows, err := s.executor(ctx, o).QueryContext(ctx, q.query, q.args...)
+	if err != nil {
+		return fmt.Errorf("%s, %s", err, q.query)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		field, ok := table.FieldsMap[column]
+		if !ok {
+			return &UnknownColumnError{Table: table.Name, Column: column}
+		}
+		indexes[i] = field.IDx
+	}
+	scan := func(entity scanner.Tabler) error {
+		fieldsPointers := entity.FieldsPointers()
+		pointers := make([]interface{}, len(indexes))
+		for i, idx := range indexes {
+			pointers[i] = fieldsPointers[idx]
+		}
+		return rows.Scan(pointers...)
+	}
+
+	if !slice.IsValid() {
+		if !rows.Next() {
+			if err = rows.Err(); err != nil {
+				return err
+			}
+			return ErrNotFound
+		}
+		if err = scan(single); err != nil {
+			return err
+		}
+		return rows.Err()
+	}
+
+	for rows.Next() {
+		entity := reflect.New(elemType)
+		if err = scan(entity.Interface().(scanner.Tabler)); err != nil {
+			return err
+		}
+		if slice.Type().Elem().Kind() == reflect.Ptr {
+			slice.Set(reflect.Append(slice, entity))
+			continue
+		}
+		slice.Set(reflect.Append(slice, entity.Elem()))
+	}
+
+	return rows.Err()
+}
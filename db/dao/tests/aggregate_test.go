@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_Aggregate(t *testing.T) {
+	d := newDAO(t, &customerDTO{}, &purchaseDTO{})
+	ctx := context.Background()
+	seed(
+		t, d,
+		&customerDTO{Name: "ann"}, &customerDTO{Name: "bob"},
+		&purchaseDTO{CustomerID: 1, Total: 10}, &purchaseDTO{CustomerID: 1, Total: 30},
+		&purchaseDTO{CustomerID: 1, Total: 30}, &purchaseDTO{CustomerID: 2, Total: 5},
+	)
+
+	var groups []struct {
+		CustomerID int `db:"customer_id"`
+		Count      int `db:"purchases"`
+		Distinct   int `db:"distinct_totals"`
+		Sum        int `db:"sum"`
+		Max        int `db:"max"`
+	}
+	aggregate := dao.Aggregate{
+		GroupBy: []string{"customer_id"},
+		Aggregations: []dao.Aggregation{
+			dao.Count("purchases"), dao.CountDistinct("total", "distinct_totals"),
+			dao.Sum("total", "sum"), dao.Max("total", "max"),
+		},
+	}
+	condition := utils.Condition{Order: []*utils.Order{{Field: "sum", Asc: false}}}
+	if err := d.Aggregate(ctx, &groups, &purchaseDTO{}, condition, aggregate); err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].CustomerID != 1 || groups[0].Count != 3 || groups[0].Distinct != 2 ||
+		groups[0].Sum != 70 || groups[0].Max != 30 || groups[1].Sum != 5 {
+		t.Errorf("Aggregate() = %+v", groups)
+	}
+
+	var rows []map[string]interface{}
+	aggregate = dao.Aggregate{
+		GroupBy:      []string{"customers.name"},
+		Aggregations: []dao.Aggregation{dao.Avg("purchases.total", "avg")},
+		Having:       []utils.Expr{utils.Gt("avg", 10)},
+	}
+	join := dao.WithJoin(dao.Join{Table: "purchases"})
+	if err := d.Aggregate(ctx, &rows, &customerDTO{}, utils.Condition{}, aggregate, join); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0]["customers.name"] != "ann" {
+		t.Errorf("Aggregate() with having = %+v, want only ann", rows)
+	}
+
+	counts, err := dao.CountBy[int](ctx, d, &purchaseDTO{}, "total", utils.Condition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dao.GroupCount[int]{{Value: 5, Count: 1}, {Value: 10, Count: 1}, {Value: 30, Count: 2}}
+	if len(counts) != len(want) {
+		t.Fatalf("CountBy() = %+v, want %+v", counts, want)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("CountBy()[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+
+	err = d.Aggregate(ctx, &rows, &purchaseDTO{}, utils.Condition{}, dao.Aggregate{})
+	if !errors.Is(err, dao.ErrEmptyAggregate) {
+		t.Errorf("Aggregate() without columns error = %v, want ErrEmptyAggregate", err)
+	}
+	var columnErr *dao.UnknownColumnError
+	err = d.Aggregate(ctx, &rows, &purchaseDTO{}, utils.Condition{}, dao.Aggregate{Aggregations: []dao.Aggregation{dao.Sum("price", "sum")}})
+	if !errors.As(err, &columnErr) {
+		t.Errorf("Aggregate() unknown column error = %v, want UnknownColumnError", err)
+	}
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type itemDTO struct {
+	ID    int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Name  string `db:"name" db_type:"varchar(50)" db_default:"not null" db_ops:"create,update"`
+	Price int    `db:"price" db_type:"integer" db_default:"default 0" db_ops:"create,update"`
+}
+
+func (i *itemDTO) TableName() string {
+	return "items"
+}
+
+func (i *itemDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (i *itemDTO) FieldsPointers() []interface{} {
+	return []interface{}{&i.ID, &i.Name, &i.Price}
+}
+
+func newItemsDAO(t *testing.T) *dao.DAO {
+	t.Helper()
+	d := newDAO(t, &itemDTO{})
+	seed(t, d, &itemDTO{Name: "Apple", Price: 10}, &itemDTO{Name: "banana", Price: 20}, &itemDTO{Name: "Cherry", Price: 30})
+
+	return d
+}
+
+func TestDAO_ListWhere(t *testing.T) {
+	d := newItemsDAO(t)
+	tests := []struct {
+		name    string
+		where   []utils.Expr
+		want    []string
+		wantErr bool
+	}{
+		{name: "greater than", where: []utils.Expr{utils.Gt("price", 10)}, want: []string{"banana", "Cherry"}},
+		{name: "less or equal", where: []utils.Expr{utils.Lte("price", 20)}, want: []string{"Apple", "banana"}},
+		{name: "between", where: []utils.Expr{utils.Between("price", 15, 30)}, want: []string{"banana", "Cherry"}},
+		{name: "in", where: []utils.Expr{utils.In("name", []string{"Apple", "Cherry"})}, want: []string{"Apple", "Cherry"}},
+		{name: "not in", where: []utils.Expr{utils.NotIn("name", []string{"Apple"})}, want: []string{"banana", "Cherry"}},
+		{name: "ilike", where: []utils.Expr{utils.ILike("name", "%an%")}, want: []string{"banana"}},
+		{
+			name:  "or with nested and",
+			where: []utils.Expr{utils.Or(utils.Eq("name", "Apple"), utils.And(utils.Gt("price", 20), utils.Not(utils.IsNull("name"))))},
+			want:  []string{"Apple", "Cherry"},
+		},
+		{name: "in without slice", where: []utils.Expr{utils.In("name", "Apple")}, wantErr: true},
+		{name: "empty or", where: []utils.Expr{utils.Or()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var items []itemDTO
+				condition := utils.Condition{Where: tt.where, Order: []*utils.Order{{Field: "id", Asc: true}}}
+				err := d.List(context.Background(), &items, &itemDTO{}, condition)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+				if len(items) != len(tt.want) {
+					t.Fatalf("List() = %v, want %v", items, tt.want)
+				}
+				for i := range items {
+					if items[i].Name != tt.want[i] {
+						t.Errorf("List()[%d] = %s, want %s", i, items[i].Name, tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestDAO_UpdateWhere(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+	condition := utils.Condition{Where: []utils.Expr{utils.Gte("price", 20)}}
+	if err := d.Update(ctx, &itemDTO{Name: "sold", Price: 0}, condition, scanner.Update); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{Where: []utils.Expr{utils.Eq("name", "sold")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("GetCount() = %d, want 2", count)
+	}
+}
+
+func TestDAO_UnknownColumn(t *testing.T) {
+	d := newItemsDAO(t)
+	tests := []struct {
+		name      string
+		condition utils.Condition
+		column    string
+	}{
+		{
+			name:      "equal key",
+			condition: utils.Condition{Equal: map[string]interface{}{"name = name OR 1": 1}},
+			column:    "name = name OR 1",
+		},
+		{
+			name:      "order field",
+			condition: utils.Condition{Order: []*utils.Order{{Field: "(SELECT 1)"}}},
+			column:    "(SELECT 1)",
+		},
+		{
+			name:      "nested expression",
+			condition: utils.Condition{Where: []utils.Expr{utils.Not(utils.Gt("cost", 1))}},
+			column:    "cost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var items []itemDTO
+				err := d.List(context.Background(), &items, &itemDTO{}, tt.condition)
+				var columnErr *dao.UnknownColumnError
+				if !errors.As(err, &columnErr) {
+					t.Fatalf("List() error = %v, want UnknownColumnError", err)
+				}
+				if columnErr.Column != tt.column || columnErr.Table != "items" {
+					t.Errorf("UnknownColumnError = %+v, want column %q", columnErr, tt.column)
+				}
+			},
+		)
+	}
+}
+
+type entryDTO struct {
+	ID    int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Order int    `db:"order" db_type:"integer" db_ops:"create,update"`
+	Group string `db:"group" db_type:"varchar(20)" db_ops:"create,update"`
+}
+
+func (e *entryDTO) TableName() string {
+	return "entries"
+}
+
+func (e *entryDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (e *entryDTO) FieldsPointers() []interface{} {
+	return []interface{}{&e.ID, &e.Order, &e.Group}
+}
+
+func TestDAO_ReservedColumns(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	db.MustExec(`create table entries (id integer primary key, "order" integer, "group" varchar(20))`)
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&entryDTO{})
+	d := dao.NewDAO(db, utils.DB{Driver: "sqlite3"}, tableScanner)
+	ctx := context.Background()
+
+	// колонки с именами ключевых слов в списках SELECT, INSERT и SET
+	entry := entryDTO{Order: 1, Group: "a"}
+	if err := d.Create(ctx, &entry); err != nil {
+		t.Fatal(err)
+	}
+	first := utils.Condition{Equal: map[string]interface{}{"id": entry.ID}}
+	if err := d.Update(ctx, &entryDTO{Order: 2, Group: "b"}, first, scanner.Update); err != nil {
+		t.Fatal(err)
+	}
+	var got entryDTO
+	if err := d.Get(ctx, &got, first); err != nil {
+		t.Fatal(err)
+	}
+	if want := (entryDTO{ID: entry.ID, Order: 2, Group: "b"}); got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	var list []entryDTO
+	if err := d.List(ctx, &list, &entryDTO{}, utils.Condition{Order: []*utils.Order{{Field: "order", Asc: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0] != got {
+		t.Errorf("List() = %+v, want [%+v]", list, got)
+	}
+}
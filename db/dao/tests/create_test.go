@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestDAO_CreateReturning(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+
+	item := itemDTO{Name: "Date", Price: 40}
+	if err := d.Create(ctx, &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 4 {
+		t.Fatalf("Create() ID = %d, want 4", item.ID)
+	}
+
+	var got itemDTO
+	if err := d.Get(ctx, &got, utils.Condition{Equal: map[string]interface{}{"id": item.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if got != item {
+		t.Errorf("Get() = %+v, want %+v", got, item)
+	}
+}
+
+func TestDAO_CreateWithoutReturning(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	db.MustExec("create table items (id integer primary key, name varchar(50) not null, price integer default 0)")
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&itemDTO{})
+	// драйвер без RETURNING: строка вставляется, сгенерированные поля не заполняются
+	d := dao.NewDAO(db, utils.DB{Driver: dao.DriverRamsql}, tableScanner)
+	ctx := context.Background()
+
+	item := itemDTO{Name: "Date", Price: 40}
+	if err := d.Create(ctx, &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 0 {
+		t.Errorf("Create() ID = %d, want 0", item.ID)
+	}
+	count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{Equal: map[string]interface{}{"name": "Date"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("GetCount() = %d, want 1", count)
+	}
+}
+
+type labelDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id,create"`
+	Name string `db:"name" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (l *labelDTO) TableName() string {
+	return "labels"
+}
+
+func (l *labelDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (l *labelDTO) FieldsPointers() []interface{} {
+	return []interface{}{&l.ID, &l.Name}
+}
+
+type tokenDTO struct {
+	Key  string `db:"key" db_type:"varchar(36) primary key" db_ops:"id"`
+	Name string `db:"name" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (t *tokenDTO) TableName() string {
+	return "tokens"
+}
+
+func (t *tokenDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (t *tokenDTO) FieldsPointers() []interface{} {
+	return []interface{}{&t.Key, &t.Name}
+}
+
+func TestDAO_CreateMysqlGenerated(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	db.MustExec("create table labels (id integer primary key, name varchar(50))")
+	db.MustExec("create table tokens (key varchar(36) primary key default (lower(hex(randomblob(16)))), name varchar(50))")
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&labelDTO{}, &tokenDTO{})
+	// sqlite поддерживает параметры ? и LastInsertId, как mysql
+	d := dao.NewDAO(db, utils.DB{Driver: dao.DriverMysql}, tableScanner)
+	ctx := context.Background()
+
+	// ключ, переданный клиентом, не перезаписывается LastInsertId
+	label := labelDTO{ID: 42, Name: "go"}
+	if err := d.Create(ctx, &label); err != nil {
+		t.Fatal(err)
+	}
+	if label.ID != 42 {
+		t.Errorf("Create() ID = %d, want 42", label.ID)
+	}
+
+	// строковый ключ нельзя получить через LastInsertId, строка не вставляется
+	err := d.Create(ctx, &tokenDTO{Name: "api"})
+	if err == nil {
+		t.Fatal("Create() with string generated key error = nil")
+	}
+	var count int
+	if err = db.Get(&count, "select count(*) from tokens"); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("tokens count = %d, want 0", count)
+	}
+}
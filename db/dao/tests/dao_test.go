@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newDAO DAO над базой sqlite в памяти с таблицами entities, созданными мигратором.
+// Одно соединение сохраняет базу в памяти между запросами
+func newDAO(t *testing.T, entities ...scanner.Tabler) *dao.DAO {
+	t.Helper()
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(entities...)
+	dbConf := utils.DB{Driver: "sqlite3"}
+	if err := migrate.NewMigrator(db, dbConf, tableScanner).Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return dao.NewDAO(db, dbConf, tableScanner)
+}
+
+// seed вставка строк entities по порядку
+func seed(t *testing.T, d *dao.DAO, entities ...scanner.Tabler) {
+	t.Helper()
+	for _, entity := range entities {
+		if err := d.Create(context.Background(), entity); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
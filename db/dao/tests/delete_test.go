@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+type noteDTO struct {
+	ID        int          `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Title     string       `db:"title" db_type:"varchar(50)" db_default:"not null" db_ops:"create,update"`
+	DeletedAt sql.NullTime `db:"deleted_at" db_type:"timestamp" db_default:"default null" db_ops:"deleted_at"`
+}
+
+func (n *noteDTO) TableName() string {
+	return "notes"
+}
+
+func (n *noteDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (n *noteDTO) FieldsPointers() []interface{} {
+	return []interface{}{&n.ID, &n.Title, &n.DeletedAt}
+}
+
+// newNotesDAO DAO с таблицей notes (мягкое удаление) и строками a, b, c
+func newNotesDAO(t *testing.T) *dao.DAO {
+	t.Helper()
+	d := newDAO(t, &noteDTO{}, &itemDTO{})
+	seed(t, d, &noteDTO{Title: "a"}, &noteDTO{Title: "b"}, &noteDTO{Title: "c"})
+
+	return d
+}
+
+func title(value string) utils.Condition {
+	return utils.Condition{Equal: map[string]interface{}{"title": value}}
+}
+
+func TestDAO_Delete(t *testing.T) {
+	d := newNotesDAO(t)
+	ctx := context.Background()
+
+	if err := d.Delete(ctx, &noteDTO{}, title("a")); err != nil {
+		t.Fatal(err)
+	}
+	// жесткое удаление убирает строку и из выборки с удаленными
+	count, err := d.GetCount(ctx, &noteDTO{}, utils.Condition{}, dao.WithDeleted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("GetCount() after Delete = %d, want 2", count)
+	}
+
+	if err = d.Delete(ctx, &noteDTO{}, utils.Condition{}); !errors.Is(err, dao.ErrEmptyCondition) {
+		t.Errorf("Delete() without condition error = %v, want ErrEmptyCondition", err)
+	}
+	if err = d.Delete(ctx, &noteDTO{}, utils.Condition{}, dao.Force()); err != nil {
+		t.Fatal(err)
+	}
+	if count, err = d.GetCount(ctx, &noteDTO{}, utils.Condition{}, dao.WithDeleted()); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("GetCount() after forced Delete = %d, want 0", count)
+	}
+}
+
+func TestDAO_SoftDelete(t *testing.T) {
+	d := newNotesDAO(t)
+	ctx := context.Background()
+
+	if err := d.SoftDelete(ctx, &noteDTO{}, title("a")); err != nil {
+		t.Fatal(err)
+	}
+	var note noteDTO
+	if err := d.Get(ctx, &note, title("a"), dao.WithDeleted()); err != nil {
+		t.Fatal(err)
+	}
+	if !note.DeletedAt.Valid {
+		t.Errorf("SoftDelete() deleted_at = %v, want set", note.DeletedAt)
+	}
+	deletedAt := note.DeletedAt.Time
+
+	// повторное мягкое удаление не меняет отметку
+	if err := d.SoftDelete(ctx, &noteDTO{}, title("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Get(ctx, &note, title("a"), dao.WithDeleted()); err != nil {
+		t.Fatal(err)
+	}
+	if !note.DeletedAt.Time.Equal(deletedAt) {
+		t.Errorf("repeated SoftDelete() deleted_at = %v, want %v", note.DeletedAt.Time, deletedAt)
+	}
+
+	if err := d.Restore(ctx, &noteDTO{}, title("a")); err != nil {
+		t.Fatal(err)
+	}
+	note = noteDTO{}
+	if err := d.Get(ctx, &note, title("a")); err != nil {
+		t.Fatal(err)
+	}
+	if note.DeletedAt.Valid {
+		t.Errorf("Restore() deleted_at = %v, want null", note.DeletedAt)
+	}
+
+	calls := []struct {
+		name string
+		call func(opts ...dao.Option) error
+	}{
+		{name: "SoftDelete", call: func(opts ...dao.Option) error {
+			return d.SoftDelete(ctx, &noteDTO{}, utils.Condition{}, opts...)
+		}},
+		{name: "Restore", call: func(opts ...dao.Option) error {
+			return d.Restore(ctx, &noteDTO{}, utils.Condition{}, opts...)
+		}},
+		{name: "Update", call: func(opts ...dao.Option) error {
+			return d.Update(ctx, &noteDTO{Title: "all"}, utils.Condition{}, scanner.Update, opts...)
+		}},
+	}
+	for _, tt := range calls {
+		if err := tt.call(); !errors.Is(err, dao.ErrEmptyCondition) {
+			t.Errorf("%s() without condition error = %v, want ErrEmptyCondition", tt.name, err)
+		}
+		if err := tt.call(dao.Force()); err != nil {
+			t.Errorf("%s() with Force() error = %v", tt.name, err)
+		}
+	}
+
+	err := d.SoftDelete(ctx, &itemDTO{}, utils.Condition{Equal: map[string]interface{}{"id": 1}})
+	if !errors.Is(err, dao.ErrSoftDeleteUnsupported) {
+		t.Errorf("SoftDelete() without deleted_at error = %v, want ErrSoftDeleteUnsupported", err)
+	}
+	if err = d.Restore(ctx, &itemDTO{}, utils.Condition{Equal: map[string]interface{}{"id": 1}}); !errors.Is(err, dao.ErrSoftDeleteUnsupported) {
+		t.Errorf("Restore() without deleted_at error = %v, want ErrSoftDeleteUnsupported", err)
+	}
+}
+
+func TestDAO_DeletedScope(t *testing.T) {
+	d := newNotesDAO(t)
+	ctx := context.Background()
+	if err := d.SoftDelete(ctx, &noteDTO{}, title("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   []dao.Option
+		titles []string
+	}{
+		{name: "default", titles: []string{"a", "c"}},
+		{name: "WithDeleted", opts: []dao.Option{dao.WithDeleted()}, titles: []string{"a", "b", "c"}},
+		{name: "OnlyDeleted", opts: []dao.Option{dao.OnlyDeleted()}, titles: []string{"b"}},
+	}
+	byID := utils.Condition{Order: []*utils.Order{{Field: "id", Asc: true}}}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var notes []noteDTO
+				if err := d.List(ctx, &notes, &noteDTO{}, byID, tt.opts...); err != nil {
+					t.Fatal(err)
+				}
+				titles := make([]string, 0, len(notes))
+				for i := range notes {
+					titles = append(titles, notes[i].Title)
+				}
+				if !slices.Equal(titles, tt.titles) {
+					t.Errorf("List() = %v, want %v", titles, tt.titles)
+				}
+
+				count, err := d.GetCount(ctx, &noteDTO{}, utils.Condition{}, tt.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count != uint64(len(tt.titles)) {
+					t.Errorf("GetCount() = %d, want %d", count, len(tt.titles))
+				}
+
+				var note noteDTO
+				err = d.Get(ctx, &note, title("b"), tt.opts...)
+				found := slices.Contains(tt.titles, "b")
+				if found && err != nil {
+					t.Errorf("Get() deleted row error = %v", err)
+				}
+				if !found && !errors.Is(err, dao.ErrNotFound) {
+					t.Errorf("Get() deleted row error = %v, want ErrNotFound", err)
+				}
+			},
+		)
+	}
+}
+
+func TestDAO_DeletedScopeExplicit(t *testing.T) {
+	d := newNotesDAO(t)
+	ctx := context.Background()
+	if err := d.SoftDelete(ctx, &noteDTO{}, title("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	// условие на deleted_at заменяет неявное deleted_at IS NULL
+	tests := []struct {
+		name      string
+		condition utils.Condition
+		want      uint64
+	}{
+		{name: "where", condition: utils.Condition{Where: []utils.Expr{utils.IsNotNull("deleted_at")}}, want: 1},
+		{
+			name: "nested where",
+			condition: utils.Condition{
+				Where: []utils.Expr{utils.Or(utils.IsNotNull("deleted_at"), utils.Eq("title", "a"))},
+			},
+			want: 2,
+		},
+		{name: "qualified", condition: utils.Condition{Where: []utils.Expr{utils.IsNotNull("notes.deleted_at")}}, want: 1},
+		{name: "not equal", condition: utils.Condition{NotEqual: map[string]interface{}{"deleted_at": nil}}, want: 1},
+		{name: "other field", condition: utils.Condition{Where: []utils.Expr{utils.NotEq("title", "c")}}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				count, err := d.GetCount(ctx, &noteDTO{}, tt.condition)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count != tt.want {
+					t.Errorf("GetCount() = %d, want %d", count, tt.want)
+				}
+			},
+		)
+	}
+}
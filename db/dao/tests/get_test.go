@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_Get(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+
+	var item itemDTO
+	err := d.Get(ctx, &item, utils.Condition{Equal: map[string]interface{}{"name": "missing"}})
+	if !errors.Is(err, dao.ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get() missing row error = %v, want ErrNotFound wrapping sql.ErrNoRows", err)
+	}
+
+	apple := utils.Condition{Equal: map[string]interface{}{"name": "Apple"}}
+	if err = d.Get(ctx, &item, apple, dao.Unique()); err != nil {
+		t.Fatal(err)
+	}
+	if want := (itemDTO{ID: 1, Name: "Apple", Price: 10}); item != want {
+		t.Errorf("Get() unique = %+v, want %+v", item, want)
+	}
+
+	expensive := utils.Condition{
+		Where: []utils.Expr{utils.Gt("price", 10)},
+		Order: []*utils.Order{{Field: "price", Asc: true}},
+	}
+	// при нескольких строках dest остается прежним
+	item = itemDTO{ID: -1}
+	if err = d.Get(ctx, &item, expensive, dao.Unique()); !errors.Is(err, dao.ErrMultipleRows) {
+		t.Errorf("Get() unique error = %v, want ErrMultipleRows", err)
+	}
+	if want := (itemDTO{ID: -1}); item != want {
+		t.Errorf("Get() unique with multiple rows changed dest to %+v", item)
+	}
+
+	if err = d.Get(ctx, &item, expensive); err != nil {
+		t.Fatal(err)
+	}
+	if want := (itemDTO{ID: 2, Name: "banana", Price: 20}); item != want {
+		t.Errorf("Get() first = %+v, want %+v", item, want)
+	}
+}
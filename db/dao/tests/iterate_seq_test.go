@@ -0,0 +1,42 @@
+//go:build go1.23
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_Rows(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+
+	var names []string
+	for row, err := range d.Rows(ctx, &itemDTO{}, utils.Condition{Where: []utils.Expr{utils.Gte("price", 20)}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, row.(*itemDTO).Name)
+	}
+	if len(names) != 2 {
+		t.Errorf("Rows() names = %v, want two rows", names)
+	}
+
+	for range d.Rows(ctx, &itemDTO{}, utils.Condition{}) {
+		break
+	}
+	count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{})
+	if err != nil || count != 3 {
+		t.Errorf("GetCount() after break = %d, %v, want 3", count, err)
+	}
+
+	var errs []error
+	for _, err := range d.Rows(ctx, &itemDTO{}, utils.Condition{Where: []utils.Expr{utils.Eq("missing", 1)}}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Errorf("Rows() with unknown column yielded %v, want one error", errs)
+	}
+}
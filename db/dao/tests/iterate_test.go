@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_Iterate(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+	condition := utils.Condition{Order: []*utils.Order{{Field: "price", Asc: false}}}
+
+	var names []string
+	err := d.Iterate(ctx, &itemDTO{}, condition, func(row scanner.Tabler) error {
+		names = append(names, row.(*itemDTO).Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || names[0] != "Cherry" || names[2] != "Apple" {
+		t.Errorf("Iterate() names = %v, want [Cherry banana Apple]", names)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = d.Iterate(ctx, &itemDTO{}, condition, func(row scanner.Tabler) error {
+		calls++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Errorf("Iterate() error = %v after %d calls, want callback error after one call", err, calls)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	calls = 0
+	err = d.Iterate(cancelCtx, &itemDTO{}, condition, func(row scanner.Tabler) error {
+		calls++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Iterate() error = %v after %d calls, want context.Canceled after one call", err, calls)
+	}
+
+	// единственное соединение должно быть освобождено после прерванных обходов
+	count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("GetCount() = %d, want 3", count)
+	}
+}
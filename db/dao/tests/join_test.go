@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+type customerDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Name string `db:"name" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (c *customerDTO) TableName() string {
+	return "customers"
+}
+
+func (c *customerDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (c *customerDTO) FieldsPointers() []interface{} {
+	return []interface{}{&c.ID, &c.Name}
+}
+
+type purchaseDTO struct {
+	ID         int `db:"id" db_type:"integer primary key" db_ops:"id"`
+	CustomerID int `db:"customer_id" db_type:"integer" db_fk:"customers.id" db_ops:"create"`
+	Total      int `db:"total" db_type:"integer" db_ops:"create"`
+}
+
+func (p *purchaseDTO) TableName() string {
+	return "purchases"
+}
+
+func (p *purchaseDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (p *purchaseDTO) FieldsPointers() []interface{} {
+	return []interface{}{&p.ID, &p.CustomerID, &p.Total}
+}
+
+func TestDAO_ListJoin(t *testing.T) {
+	d := newDAO(t, &customerDTO{}, &purchaseDTO{})
+	ctx := context.Background()
+	seed(
+		t, d,
+		&customerDTO{Name: "ann"}, &customerDTO{Name: "bob"},
+		&purchaseDTO{CustomerID: 1, Total: 10}, &purchaseDTO{CustomerID: 1, Total: 30}, &purchaseDTO{CustomerID: 2, Total: 5},
+	)
+
+	var rows []struct {
+		Purchase purchaseDTO `db:"purchases"`
+		Customer customerDTO `db:"customers"`
+	}
+	condition := utils.Condition{
+		Where: []utils.Expr{utils.Gte("total", 10), utils.Eq("customers.name", "ann")},
+		Order: []*utils.Order{{Field: "total"}},
+	}
+	if err := d.List(ctx, &rows, &purchaseDTO{}, condition, dao.WithJoin(dao.Join{Table: "customers"})); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Purchase.Total != 30 || rows[1].Customer.Name != "ann" {
+		t.Errorf("List() = %+v, want two ann purchases ordered by total desc", rows)
+	}
+
+	var totals []struct {
+		Name  string `db:"name"`
+		Total int    `db:"purchases.total"`
+	}
+	join := dao.WithJoin(dao.Join{Type: dao.JoinLeft, Table: "purchases"})
+	condition = utils.Condition{Order: []*utils.Order{{Field: "purchases.total", Asc: true}}}
+	if err := d.List(ctx, &totals, &customerDTO{}, condition, join, dao.WithColumns("name", "purchases.total")); err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 3 || totals[0].Name != "bob" || totals[2].Total != 30 {
+		t.Errorf("List() = %+v, want totals ordered ascending", totals)
+	}
+
+	var columnErr *dao.UnknownColumnError
+	err := d.List(ctx, &totals, &customerDTO{}, utils.Condition{}, dao.WithColumns("purchases.total"))
+	if !errors.As(err, &columnErr) {
+		t.Errorf("List() without join error = %v, want UnknownColumnError", err)
+	}
+
+	count, err := d.GetCount(ctx, &customerDTO{}, utils.Condition{Where: []utils.Expr{utils.Lt("purchases.total", 20)}}, join)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("GetCount() = %d, want 2", count)
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_Options(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+	apple := utils.Condition{Equal: map[string]interface{}{"name": "Apple"}}
+
+	// WithColumns ограничивает изменяемые и выбираемые колонки
+	err := d.Update(ctx, &itemDTO{Name: "ignored", Price: 15}, apple, scanner.Update, dao.WithColumns("price"), dao.WithComment("options test */"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item itemDTO
+	if err = d.Get(ctx, &item, apple, dao.WithColumns("id", "price"), dao.WithTimeout(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (itemDTO{ID: 1, Price: 15}); item != want {
+		t.Errorf("Get() = %+v, want %+v", item, want)
+	}
+
+	var columnErr *dao.UnknownColumnError
+	if err = d.Get(ctx, &item, apple, dao.WithColumns("cost")); !errors.As(err, &columnErr) {
+		t.Errorf("Get() error = %v, want UnknownColumnError", err)
+	}
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	if err = d.Get(expired, &item, apple); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestOptionsFrom(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	// прежние вызовы передают транзакцию *sqlx.Tx в нетипизированном срезе опций
+	err := d.WithTx(ctx, func(txCtx context.Context) error {
+		opts := dao.OptionsFrom(dao.TxFromContext(txCtx), dao.Force(), nil)
+		if err := d.Update(ctx, &itemDTO{Name: "all", Price: 1}, utils.Condition{}, scanner.Update, opts...); err != nil {
+			return err
+		}
+		count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{Equal: map[string]interface{}{"name": "all"}}, opts[0])
+		if err != nil {
+			return err
+		}
+		if count != 3 {
+			t.Errorf("GetCount() in tx = %d, want 3", count)
+		}
+		return errRollback
+	}, nil)
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want %v", err, errRollback)
+	}
+
+	count, err := d.GetCount(ctx, &itemDTO{}, utils.Condition{Equal: map[string]interface{}{"name": "all"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("GetCount() after rollback = %d, want 0", count)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("OptionsFrom() with unsupported value did not panic")
+		}
+	}()
+	dao.OptionsFrom(&sql.Tx{})
+}
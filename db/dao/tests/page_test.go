@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDAO_Page(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+	for _, item := range []itemDTO{{Name: "Date", Price: 20}, {Name: "Fig", Price: 40}} {
+		if err := d.Create(ctx, &item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func(items []itemDTO) []string {
+		result := make([]string, 0, len(items))
+		for i := range items {
+			result = append(result, items[i].Name)
+		}
+		return result
+	}
+	equal := func(got, want []string) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+	// цены 40, 30, 20, 20, 10: одинаковые цены упорядочиваются по id
+	condition := utils.Condition{Order: []*utils.Order{{Field: "price", Asc: false}}}
+
+	var page []itemDTO
+	first, err := d.Page(ctx, &page, &itemDTO{}, condition, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Fig", "Cherry"}; !equal(names(page), want) || first.Next == "" || first.Prev != "" {
+		t.Fatalf("first page = %v %+v, want %v with next cursor only", names(page), first, want)
+	}
+
+	page = nil
+	second, err := d.Page(ctx, &page, &itemDTO{}, condition, first.Next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"banana", "Date"}; !equal(names(page), want) || second.Next == "" || second.Prev == "" {
+		t.Fatalf("second page = %v %+v, want %v with both cursors", names(page), second, want)
+	}
+
+	page = nil
+	last, err := d.Page(ctx, &page, &itemDTO{}, condition, second.Next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Apple"}; !equal(names(page), want) || last.Next != "" || last.Prev == "" {
+		t.Fatalf("last page = %v %+v, want %v with prev cursor only", names(page), last, want)
+	}
+
+	page = nil
+	back, err := d.Page(ctx, &page, &itemDTO{}, condition, last.Prev, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"banana", "Date"}; !equal(names(page), want) || back.Next == "" || back.Prev == "" {
+		t.Fatalf("page before last = %v %+v, want %v", names(page), back, want)
+	}
+
+	page = nil
+	back, err = d.Page(ctx, &page, &itemDTO{}, condition, back.Prev, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Fig", "Cherry"}; !equal(names(page), want) || back.Prev != "" {
+		t.Fatalf("first page backwards = %v %+v, want %v without prev cursor", names(page), back, want)
+	}
+
+	tampered := []byte(first.Next)
+	tampered[3] ^= 1
+	if _, err = d.Page(ctx, &page, &itemDTO{}, condition, string(tampered), 2); !errors.Is(err, dao.ErrInvalidCursor) {
+		t.Errorf("Page() with tampered cursor error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err = d.Page(ctx, &page, &itemDTO{}, utils.Condition{}, first.Next, 2); !errors.Is(err, dao.ErrInvalidCursor) {
+		t.Errorf("Page() with another order error = %v, want ErrInvalidCursor", err)
+	}
+
+	filtered := condition
+	filtered.Where = []utils.Expr{utils.Gt("price", 10)}
+	if _, err = d.Page(ctx, &page, &itemDTO{}, filtered, first.Next, 2); !errors.Is(err, dao.ErrInvalidCursor) {
+		t.Errorf("Page() with another condition error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err = d.Page(ctx, &page, &itemDTO{}, condition, first.Next, 2, dao.WithComment("page")); err != nil {
+		t.Errorf("Page() with the same condition and other options error = %v", err)
+	}
+
+	var limited []itemDTO
+	condition.LimitOffset = &utils.LimitOffset{Limit: 2, Offset: 1}
+	if err = d.List(ctx, &limited, &itemDTO{}, condition); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Cherry", "banana"}; !equal(names(limited), want) {
+		t.Errorf("List() with limit and offset = %v, want %v", names(limited), want)
+	}
+}
+
+func TestDAO_PageWithoutSecret(t *testing.T) {
+	d := newItemsDAO(t)
+	core, logs := observer.New(zap.WarnLevel)
+	d.SetLogger(zap.New(core))
+	ctx := context.Background()
+
+	// предупреждение о случайном ключе выводится один раз
+	for i := 0; i < 2; i++ {
+		var page []itemDTO
+		if _, err := d.Page(ctx, &page, &itemDTO{}, utils.Condition{}, "", 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if logs.Len() != 1 {
+		t.Errorf("warnings = %v, want one cursor secret warning", logs.All())
+	}
+}
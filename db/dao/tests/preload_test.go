@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+type authorDTO struct {
+	ID      int         `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Name    string      `db:"name" db_type:"varchar(50)" db_ops:"create"`
+	Books   []*bookDTO  `db:"-" db_rel:"has_many,author_id"`
+	Profile *profileDTO `db:"-" db_rel:"has_one,author_id"`
+}
+
+func (a *authorDTO) TableName() string {
+	return "authors"
+}
+
+func (a *authorDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (a *authorDTO) FieldsPointers() []interface{} {
+	return []interface{}{&a.ID, &a.Name, &a.Books, &a.Profile}
+}
+
+type profileDTO struct {
+	ID       int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	AuthorID int    `db:"author_id" db_type:"integer" db_fk:"authors.id" db_ops:"create"`
+	Bio      string `db:"bio" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (p *profileDTO) TableName() string {
+	return "profiles"
+}
+
+func (p *profileDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (p *profileDTO) FieldsPointers() []interface{} {
+	return []interface{}{&p.ID, &p.AuthorID, &p.Bio}
+}
+
+type bookDTO struct {
+	ID       int      `db:"id" db_type:"integer primary key" db_ops:"id"`
+	AuthorID int      `db:"author_id" db_type:"integer" db_fk:"authors.id" db_ops:"create"`
+	Title    string   `db:"title" db_type:"varchar(50)" db_ops:"create"`
+	Tags     []tagDTO `db:"-" db_rel:"many_to_many,book_tags,book_id,tag_id"`
+}
+
+func (b *bookDTO) TableName() string {
+	return "books"
+}
+
+func (b *bookDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (b *bookDTO) FieldsPointers() []interface{} {
+	return []interface{}{&b.ID, &b.AuthorID, &b.Title, &b.Tags}
+}
+
+type tagDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Name string `db:"name" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (t *tagDTO) TableName() string {
+	return "tags"
+}
+
+func (t *tagDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (t *tagDTO) FieldsPointers() []interface{} {
+	return []interface{}{&t.ID, &t.Name}
+}
+
+type bookTagDTO struct {
+	BookID int `db:"book_id" db_type:"integer" db_ops:"create"`
+	TagID  int `db:"tag_id" db_type:"integer" db_ops:"create"`
+}
+
+func (b *bookTagDTO) TableName() string {
+	return "book_tags"
+}
+
+func (b *bookTagDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (b *bookTagDTO) FieldsPointers() []interface{} {
+	return []interface{}{&b.BookID, &b.TagID}
+}
+
+func TestDAO_ListPreload(t *testing.T) {
+	d := newDAO(t, &authorDTO{}, &profileDTO{}, &bookDTO{}, &tagDTO{}, &bookTagDTO{})
+	ctx := context.Background()
+	seed(
+		t, d,
+		&authorDTO{Name: "ann"}, &authorDTO{Name: "bob"}, &authorDTO{Name: "eve"},
+		&profileDTO{AuthorID: 2, Bio: "poet"},
+		&bookDTO{AuthorID: 1, Title: "a1"}, &bookDTO{AuthorID: 1, Title: "a2"}, &bookDTO{AuthorID: 2, Title: "b1"},
+		&tagDTO{Name: "new"}, &tagDTO{Name: "classic"},
+		&bookTagDTO{BookID: 1, TagID: 1}, &bookTagDTO{BookID: 1, TagID: 2}, &bookTagDTO{BookID: 3, TagID: 2},
+	)
+
+	var authors []authorDTO
+	condition := utils.Condition{Order: []*utils.Order{{Field: "id", Asc: true}}}
+	if err := d.List(ctx, &authors, &authorDTO{}, condition, dao.Preload("Books.Tags", "Profile")); err != nil {
+		t.Fatal(err)
+	}
+	if len(authors) != 3 {
+		t.Fatalf("got %d authors", len(authors))
+	}
+
+	ann, bob, eve := authors[0], authors[1], authors[2]
+	if len(ann.Books) != 2 || ann.Books[0].Title != "a1" || ann.Books[1].Title != "a2" {
+		t.Fatalf("unexpected ann books %+v", ann.Books)
+	}
+	if tags := ann.Books[0].Tags; len(tags) != 2 || tags[0].Name != "new" || tags[1].Name != "classic" {
+		t.Fatalf("unexpected a1 tags %+v", tags)
+	}
+	if tags := ann.Books[1].Tags; tags == nil || len(tags) != 0 {
+		t.Fatalf("expected empty a2 tags, got %+v", tags)
+	}
+	if len(bob.Books) != 1 || len(bob.Books[0].Tags) != 1 || bob.Books[0].Tags[0].Name != "classic" {
+		t.Fatalf("unexpected bob books %+v", bob.Books)
+	}
+	if bob.Profile == nil || bob.Profile.Bio != "poet" || ann.Profile != nil {
+		t.Fatalf("unexpected profiles %+v %+v", ann.Profile, bob.Profile)
+	}
+	if eve.Books == nil || len(eve.Books) != 0 {
+		t.Fatalf("expected empty eve books, got %+v", eve.Books)
+	}
+
+	err := d.List(ctx, &authors, &authorDTO{}, utils.Condition{}, dao.Preload("Orders"))
+	if !errors.Is(err, dao.ErrUnknownRelation) {
+		t.Fatalf("expected ErrUnknownRelation, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+)
+
+func TestDAO_Raw(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+
+	var items []*itemDTO
+	query := "SELECT name, price FROM items WHERE price > ? AND name <> '??' ORDER BY price DESC"
+	if err := d.Raw(ctx, query, 10).Scan(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Name != "Cherry" || items[0].Price != 30 || items[0].ID != 0 {
+		t.Errorf("Raw() = %+v, want Cherry and banana without id", items)
+	}
+
+	var item itemDTO
+	if err := d.Raw(ctx, "SELECT id, name FROM items WHERE name = ?", "banana").Scan(&item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 2 || item.Name != "banana" {
+		t.Errorf("Raw() = %+v, want banana", item)
+	}
+	err := d.Raw(ctx, "SELECT id FROM items WHERE name = ?", "kiwi").Scan(&item)
+	if !errors.Is(err, dao.ErrNotFound) {
+		t.Errorf("Raw() without rows error = %v, want ErrNotFound", err)
+	}
+	var columnErr *dao.UnknownColumnError
+	err = d.Raw(ctx, "SELECT id, price * 2 AS double FROM items").Scan(&items)
+	if !errors.As(err, &columnErr) || columnErr.Column != "double" {
+		t.Errorf("Raw() with unknown column error = %v, want UnknownColumnError", err)
+	}
+
+	// откат транзакции отменяет изменение, выполненное Exec в транзакции контекста
+	errRollback := errors.New("rollback")
+	err = d.WithTx(ctx, func(ctx context.Context) error {
+		res, err := d.Exec(ctx, "UPDATE items SET price = price + ? WHERE name = ?", 5, "Apple")
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected != 1 {
+			t.Errorf("Exec() affected %d rows, want 1", affected)
+		}
+		var updated itemDTO
+		if err = d.Raw(ctx, "SELECT price FROM items WHERE name = ?", "Apple").Scan(&updated); err != nil {
+			return err
+		}
+		if updated.Price != 15 {
+			t.Errorf("Raw() in transaction price = %d, want 15", updated.Price)
+		}
+		return errRollback
+	}, nil)
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want rollback error", err)
+	}
+	if err = d.Raw(ctx, "SELECT price FROM items WHERE name = ?", "Apple").Scan(&item); err != nil || item.Price != 10 {
+		t.Errorf("Raw() after rollback price = %d, %v, want 10", item.Price, err)
+	}
+}
+
+func TestDAO_RawWith(t *testing.T) {
+	d := newItemsDAO(t)
+	ctx := context.Background()
+
+	// WithTx выполняет запросы в переданной транзакции без транзакции в контексте
+	errRollback := errors.New("rollback")
+	err := d.WithTx(ctx, func(txCtx context.Context) error {
+		tx := dao.TxFromContext(txCtx)
+		_, err := d.ExecWith(ctx, []dao.Option{dao.WithTx(tx), dao.WithTimeout(time.Second)}, "UPDATE items SET price = ? WHERE name = ?", 50, "Apple")
+		if err != nil {
+			return err
+		}
+		var item itemDTO
+		err = d.RawWith(ctx, []dao.Option{dao.WithTx(tx), dao.WithComment("raw")}, "SELECT price FROM items WHERE name = ?", "Apple").Scan(&item)
+		if err != nil {
+			return err
+		}
+		if item.Price != 50 {
+			t.Errorf("RawWith() price = %d, want 50", item.Price)
+		}
+		return errRollback
+	}, nil)
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want rollback error", err)
+	}
+
+	var item itemDTO
+	if err = d.Raw(ctx, "SELECT price FROM items WHERE name = ?", "Apple").Scan(&item); err != nil || item.Price != 10 {
+		t.Errorf("Raw() after rollback price = %d, %v, want 10", item.Price, err)
+	}
+
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	err = d.RawWith(expired, []dao.Option{dao.WithTimeout(time.Second)}, "SELECT price FROM items").Scan(&item)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("RawWith() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
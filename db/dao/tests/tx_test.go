@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+func TestDAO_WithTx(t *testing.T) {
+	errRollback := errors.New("rollback")
+	d := newProductsDAO(t)
+	ctx := context.Background()
+	err := d.WithTx(
+		ctx, func(ctx context.Context) error {
+			if err := d.Create(ctx, &productDTO{Code: "outer"}); err != nil {
+				return err
+			}
+			// вложенная ошибка откатывает только точку сохранения
+			err := d.WithTx(
+				ctx, func(ctx context.Context) error {
+					if err := d.Create(ctx, &productDTO{Code: "inner"}); err != nil {
+						return err
+					}
+					return errRollback
+				}, nil,
+			)
+			if !errors.Is(err, errRollback) {
+				t.Errorf("nested WithTx() error = %v, want %v", err, errRollback)
+			}
+			return d.WithTx(
+				ctx, func(ctx context.Context) error {
+					return d.Create(ctx, &productDTO{Code: "released"})
+				}, nil,
+			)
+		}, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var products []productDTO
+	if err = d.List(ctx, &products, &productDTO{}, utils.Condition{Order: []*utils.Order{{Field: "code", Asc: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 2 || products[0].Code != "outer" || products[1].Code != "released" {
+		t.Errorf("List() = %+v, want outer and released", products)
+	}
+
+	err = d.WithTx(
+		ctx, func(ctx context.Context) error {
+			if err := d.Create(ctx, &productDTO{Code: "discarded"}); err != nil {
+				return err
+			}
+			return errRollback
+		}, nil,
+	)
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithTx() error = %v, want %v", err, errRollback)
+	}
+	count, err := d.GetCount(ctx, &productDTO{}, utils.Condition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("GetCount() after rollback = %d, want 2", count)
+	}
+}
+
+func TestDAO_LockRequiresTx(t *testing.T) {
+	d := newProductsDAO(t)
+	ctx := context.Background()
+	if err := d.Create(ctx, &productDTO{Code: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var products []productDTO
+	err := d.List(ctx, &products, &productDTO{}, utils.Condition{ForUpdate: true})
+	if !errors.Is(err, dao.ErrLockWithoutTx) {
+		t.Fatalf("List() error = %v, want %v", err, dao.ErrLockWithoutTx)
+	}
+
+	// sqlite не поддерживает блокировку строк, в транзакции она пропускается
+	err = d.WithTx(
+		ctx, func(ctx context.Context) error {
+			var product productDTO
+			return d.Get(ctx, &product, utils.Condition{}, dao.WithLock(dao.LockForNoKeyUpdate, dao.LockSkipLocked))
+		}, nil,
+	)
+	if err != nil {
+		t.Errorf("Get() in transaction error = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+type productDTO struct {
+	ID    int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Code  string `db:"code" db_type:"varchar(20)" db_default:"not null" db_index:"index,unique" db_ops:"create"`
+	Price int    `db:"price" db_type:"integer" db_default:"default 0" db_ops:"create,upsert"`
+}
+
+func (p *productDTO) TableName() string {
+	return "products"
+}
+
+func (p *productDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (p *productDTO) FieldsPointers() []interface{} {
+	return []interface{}{&p.ID, &p.Code, &p.Price}
+}
+
+type ambiguousDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Code string `db:"code" db_type:"varchar(20)" db_index:"index,unique" db_ops:"create,upsert"`
+	Slug string `db:"slug" db_type:"varchar(20)" db_index:"index,unique" db_ops:"create,upsert"`
+}
+
+func (a *ambiguousDTO) TableName() string {
+	return "ambiguous"
+}
+
+func (a *ambiguousDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (a *ambiguousDTO) FieldsPointers() []interface{} {
+	return []interface{}{&a.ID, &a.Code, &a.Slug}
+}
+
+func newProductsDAO(t *testing.T) *dao.DAO {
+	t.Helper()
+	return newDAO(t, &productDTO{}, &ambiguousDTO{})
+}
+
+func TestDAO_Upsert(t *testing.T) {
+	d := newProductsDAO(t)
+	ctx := context.Background()
+
+	entities := []scanner.Tabler{&productDTO{Code: "a", Price: 1}, &productDTO{Code: "b", Price: 2}}
+	if err := d.Upsert(ctx, entities); err != nil {
+		t.Fatal(err)
+	}
+	entities = []scanner.Tabler{&productDTO{Code: "a", Price: 10}, &productDTO{Code: "c", Price: 3}}
+	if err := d.Upsert(ctx, entities); err != nil {
+		t.Fatal(err)
+	}
+
+	var result dao.UpsertResult
+	entities = []scanner.Tabler{&productDTO{Code: "b", Price: 20}, &productDTO{Code: "d", Price: 4}}
+	if err := d.Upsert(ctx, entities, dao.DoNothing(), dao.WithUpsertResult(&result)); err != nil {
+		t.Fatal(err)
+	}
+	if want := (dao.UpsertResult{Affected: 1, Inserted: 1, Counted: true}); result != want {
+		t.Errorf("Upsert() result = %+v, want %+v", result, want)
+	}
+
+	var products []productDTO
+	if err := d.List(ctx, &products, &productDTO{}, utils.Condition{Order: []*utils.Order{{Field: "code", Asc: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"a": 10, "b": 2, "c": 3, "d": 4}
+	if len(products) != len(want) {
+		t.Fatalf("List() = %+v, want %v", products, want)
+	}
+	for _, product := range products {
+		if product.Price != want[product.Code] {
+			t.Errorf("product %s price = %d, want %d", product.Code, product.Price, want[product.Code])
+		}
+	}
+}
+
+func TestDAO_UpsertAmbiguousConflict(t *testing.T) {
+	d := newProductsDAO(t)
+	err := d.Upsert(context.Background(), []scanner.Tabler{&ambiguousDTO{Code: "a", Slug: "a"}})
+	if !errors.Is(err, dao.ErrNoConflictTarget) {
+		t.Errorf("Upsert() error = %v, want ErrNoConflictTarget", err)
+	}
+}
+
+func TestDAO_CreateManyChunks(t *testing.T) {
+	ctx := context.Background()
+	products := func(codes ...string) []scanner.Tabler {
+		entities := make([]scanner.Tabler, 0, len(codes))
+		for _, code := range codes {
+			entities = append(entities, &productDTO{Code: code})
+		}
+		return entities
+	}
+	codes := make([]string, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		codes = append(codes, fmt.Sprintf("p%04d", i))
+	}
+
+	d := newProductsDAO(t)
+	if err := d.CreateMany(ctx, products(codes...)); err != nil {
+		t.Fatal(err)
+	}
+	count, err := d.GetCount(ctx, &productDTO{}, utils.Condition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1200 {
+		t.Fatalf("GetCount() = %d, want 1200", count)
+	}
+
+	// дубликат в последней пачке откатывает всю вставку
+	d = newProductsDAO(t)
+	err = d.CreateMany(ctx, products(append(codes, "p0000")...))
+	var chunkErr *dao.ChunkError
+	if !errors.As(err, &chunkErr) || chunkErr.Chunk != 2 {
+		t.Fatalf("CreateMany() error = %v, want ChunkError of chunk 2", err)
+	}
+	if count, _ = d.GetCount(ctx, &productDTO{}, utils.Condition{}); count != 0 {
+		t.Errorf("GetCount() after rollback = %d, want 0", count)
+	}
+
+	// без общей транзакции успешные пачки сохраняются
+	err = d.CreateMany(ctx, products(append(codes, "p0000")...), dao.SeparateChunks())
+	var batchErr *dao.BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Chunks) != 1 {
+		t.Fatalf("CreateMany() error = %v, want BatchError with one chunk", err)
+	}
+	if count, _ = d.GetCount(ctx, &productDTO{}, utils.Condition{}); count != 998 {
+		t.Errorf("GetCount() with separate chunks = %d, want 998", count)
+	}
+}
+
+func TestDAO_SeparateChunksInTx(t *testing.T) {
+	ctx := context.Background()
+	d := newProductsDAO(t)
+	// RAISE(FAIL) оставляет строки, вставленные запросом до ошибки, их отменяет только точка сохранения
+	if _, err := d.Exec(ctx, "CREATE TRIGGER products_bad BEFORE INSERT ON products WHEN NEW.code = 'bad' BEGIN SELECT RAISE(FAIL, 'bad code'); END"); err != nil {
+		t.Fatal(err)
+	}
+	entities := make([]scanner.Tabler, 0, 1200)
+	for i := 0; i < 1200; i++ {
+		code := fmt.Sprintf("p%04d", i)
+		if i == 600 {
+			code = "bad"
+		}
+		entities = append(entities, &productDTO{Code: code})
+	}
+
+	err := d.WithTx(ctx, func(ctx context.Context) error {
+		err := d.CreateMany(ctx, entities, dao.SeparateChunks())
+		var batchErr *dao.BatchError
+		if !errors.As(err, &batchErr) || len(batchErr.Chunks) != 1 || batchErr.Chunks[0].Chunk != 1 {
+			t.Errorf("CreateMany() error = %v, want BatchError of chunk 1", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// пачка с ошибкой отменена целиком, остальные пачки зафиксированы с транзакцией
+	count, err := d.GetCount(ctx, &productDTO{}, utils.Condition{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1200-499 {
+		t.Errorf("GetCount() = %d, want %d", count, 1200-499)
+	}
+}
@@ -0,0 +1,135 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// executor общий интерфейс выполнения запросов *sqlx.DB и *sqlx.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// txKey ключ транзакции в контексте
+type txKey struct{}
+
+// txState транзакция контекста и счетчик точек сохранения вложенных вызовов WithTx
+type txState struct {
+	tx         *sqlx.Tx
+	savepoints int
+}
+
+// ContextWithTx контекст с транзакцией, которую используют вызовы DAO с этим контекстом
+func ContextWithTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &txState{tx: tx})
+}
+
+// TxFromContext транзакция контекста, nil вне транзакции
+func TxFromContext(ctx context.Context) *sqlx.Tx {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+
+	return nil
+}
+
+// executor исполнитель запроса: транзакция из опций, транзакция контекста или база данных,
+// с опцией WithComment запросы предваряются комментарием
+func (s *DAO) executor(ctx context.Context, o *options) executor {
+	var exec executor = s.db
+	if o.tx != nil {
+		exec = o.tx
+	} else if tx := TxFromContext(ctx); tx != nil {
+		exec = tx
+	}
+	if o.comment != "" {
+		return commentExecutor{executor: exec, comment: "/* " + o.comment + " */ "}
+	}
+
+	return exec
+}
+
+// commentExecutor исполнитель, добавляющий комментарий в начало запроса
+type commentExecutor struct {
+	executor
+	comment string
+}
+
+func (e commentExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.executor.ExecContext(ctx, e.comment+query, args...)
+}
+
+func (e commentExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.executor.QueryContext(ctx, e.comment+query, args...)
+}
+
+func (e commentExecutor) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return e.executor.QueryxContext(ctx, e.comment+query, args...)
+}
+
+func (e commentExecutor) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return e.executor.QueryRowxContext(ctx, e.comment+query, args...)
+}
+
+func (e commentExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return e.executor.SelectContext(ctx, dest, e.comment+query, args...)
+}
+
+// WithTx выполнение fn в транзакции: фиксация при успехе, откат при ошибке или панике.
+// Транзакция передается в fn через контекст, поэтому вызовы DAO с этим контекстом
+// выполняются в ней. Вложенный вызов создает точку сохранения и при ошибке
+// откатывается только к ней, txOpts вложенного вызова не применяются
+func (s *DAO) WithTx(ctx context.Context, fn func(ctx context.Context) error, txOpts *sql.TxOptions) (err error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return s.withSavepoint(ctx, state, fn)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, txOpts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(ContextWithTx(ctx, tx))
+}
+
+// withSavepoint выполнение вложенного fn в точке сохранения транзакции
+func (s *DAO) withSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	state.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", state.savepoints)
+	if _, err = state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = state.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+		if err != nil {
+			if _, rollbackErr := state.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+				err = fmt.Errorf("%w; rollback to savepoint: %s", err, rollbackErr)
+			}
+			return
+		}
+		_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+	}()
+
+	return fn(ctx)
+}
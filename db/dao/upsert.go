@@ -0,0 +1,223 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+)
+
+// ErrNoConflictTarget ошибка отсутствия колонок конфликта для upsert
+var ErrNoConflictTarget = errors.New("dao: upsert: no conflict target")
+
+// UpsertResult результат Upsert, заполняется с опцией WithUpsertResult.
+// Inserted и Updated заполняются, если драйвер позволяет их различить (Counted):
+// postgres всегда, mysql и sqlite только с опцией DoNothing
+type UpsertResult struct {
+	Affected int64
+	Inserted int64
+	Updated  int64
+	Counted  bool
+}
+
+// Upsert вставка строк с обновлением полей scanner.Upsert при конфликте,
+// колонки конфликта берутся из scanner.Conflict, иначе из единственного уникального индекса,
+// строки вставляются пачками с учетом ограничения параметров драйвера
+func (s *DAO) Upsert(ctx context.Context, entities []scanner.Tabler, opts ...Option) error {
+	if len(entities) < 1 {
+		return fmt.Errorf("SQL adapter: zero entities passed")
+	}
+	ctx, o, cancel := s.prepare(ctx, opts)
+	defer cancel()
+
+	createFields, _, err := s.operationFields(entities[0], scanner.Create, o)
+	if err != nil {
+		return err
+	}
+	if len(createFields) < 1 {
+		return fmt.Errorf("SQL adapter: no create fields in %s", entities[0].TableName())
+	}
+
+	result := o.upsertResult
+	if result != nil {
+		*result = UpsertResult{Counted: true}
+	}
+
+	return s.inChunks(
+		ctx, len(entities), len(createFields), func(ctx context.Context, offset, count int) error {
+			var chunkResult *UpsertResult
+			if result != nil {
+				chunkResult = &UpsertResult{}
+			}
+			if err := s.upsertChunk(ctx, entities[offset:offset+count], createFields, chunkResult, o); err != nil {
+				return err
+			}
+			if result != nil {
+				result.Affected += chunkResult.Affected
+				result.Inserted += chunkResult.Inserted
+				result.Updated += chunkResult.Updated
+				result.Counted = result.Counted && chunkResult.Counted
+			}
+
+			return nil
+		}, o,
+	)
+}
+
+// upsertChunk вставка пачки строк одним запросом, result заполняется, если не nil
+func (s *DAO) upsertChunk(ctx context.Context, entities []scanner.Tabler, createFields []string, result *UpsertResult, o *options) error {
+	tableName := entities[0].TableName()
+	queryRaw := s.sqlBuilder.Insert(s.dbConf.QualifiedName(tableName)).Columns(s.quoteColumns(createFields)...)
+
+	for i := range entities {
+		_, createFieldsPointers, err := s.operationFields(entities[i], scanner.Create, o)
+		if err != nil {
+			return err
+		}
+		queryRaw = queryRaw.Values(createFieldsPointers...)
+	}
+
+	doNothing := o.doNothing
+	upsertFields, _ := s.getFields(entities[0], scanner.Upsert)
+	if len(upsertFields) < 1 {
+		doNothing = true
+	}
+	conflictFields, err := s.conflictTarget(tableName, doNothing)
+	if err != nil {
+		return err
+	}
+
+	switch s.dbConf.Driver {
+	case DriverMysql:
+		queryRaw = queryRaw.Suffix(s.onDuplicateKey(createFields, upsertFields, doNothing))
+	default:
+		queryRaw = queryRaw.Suffix(s.onConflict(conflictFields, upsertFields, doNothing))
+	}
+
+	returning := result != nil && s.dbConf.Driver == DriverPostgres
+	if returning {
+		queryRaw = queryRaw.Suffix("RETURNING (xmax = 0)")
+	}
+
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return err
+	}
+
+	exec := s.executor(ctx, o)
+	if returning {
+		rows, err := exec.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		*result = UpsertResult{Counted: true}
+		for rows.Next() {
+			var inserted bool
+			if err = rows.Scan(&inserted); err != nil {
+				return err
+			}
+			result.Affected++
+			if inserted {
+				result.Inserted++
+			} else {
+				result.Updated++
+			}
+		}
+		return rows.Err()
+	}
+
+	res, err := exec.ExecContext(ctx, query, args...)
+	if err != nil || result == nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	*result = UpsertResult{Affected: affected}
+	if doNothing {
+		// пропущенные строки не учитываются в RowsAffected
+		result.Inserted, result.Counted = affected, true
+	}
+
+	return nil
+}
+
+// conflictTarget колонки конфликта: поля scanner.Conflict, иначе поле
+// единственного уникального индекса, для DO NOTHING колонки необязательны
+func (s *DAO) conflictTarget(tableName string, doNothing bool) ([]string, error) {
+	table := s.scanner.Table(tableName)
+	var fields []string
+	for _, field := range table.OperationFields[scanner.Conflict] {
+		fields = append(fields, field.Name)
+	}
+	if len(fields) > 0 || s.dbConf.Driver == DriverMysql {
+		return fields, nil
+	}
+
+	for _, constraint := range table.Constraints {
+		if constraint.Unique {
+			fields = append(fields, constraint.Field.Name)
+		}
+	}
+	switch {
+	case len(fields) == 1:
+		return fields, nil
+	case doNothing:
+		return nil, nil
+	case len(fields) > 1:
+		return nil, fmt.Errorf("%w: %s has several unique indexes (%s), tag one with db_ops:\"conflict\"",
+			ErrNoConflictTarget, tableName, strings.Join(fields, ", "))
+	}
+
+	return nil, fmt.Errorf("%w: %s has no unique index, tag columns with db_ops:\"conflict\"", ErrNoConflictTarget, tableName)
+}
+
+// onConflict окончание запроса upsert postgres и sqlite
+func (s *DAO) onConflict(conflictFields, upsertFields []string, doNothing bool) string {
+	var clause strings.Builder
+	clause.WriteString("ON CONFLICT")
+	if len(conflictFields) > 0 {
+		columns := make([]string, 0, len(conflictFields))
+		for i := range conflictFields {
+			columns = append(columns, s.quoteIdentifier(conflictFields[i]))
+		}
+		clause.WriteString(" (" + strings.Join(columns, ", ") + ")")
+	}
+	if doNothing {
+		clause.WriteString(" DO NOTHING")
+		return clause.String()
+	}
+
+	sets := make([]string, 0, len(upsertFields))
+	for i := range upsertFields {
+		column := s.quoteIdentifier(upsertFields[i])
+		sets = append(sets, fmt.Sprintf("%s = excluded.%s", column, column))
+	}
+	clause.WriteString(" DO UPDATE SET " + strings.Join(sets, ", "))
+
+	return clause.String()
+}
+
+// onDuplicateKey окончание запроса upsert mysql, для DO NOTHING первая колонка
+// присваивается сама себе, что не меняет строку, в отличие от INSERT IGNORE,
+// который скрывает и другие ошибки
+func (s *DAO) onDuplicateKey(createFields, upsertFields []string, doNothing bool) string {
+	if doNothing {
+		column := s.quoteIdentifier(createFields[0])
+		return fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s", column, column)
+	}
+
+	sets := make([]string, 0, len(upsertFields))
+	for i := range upsertFields {
+		column := s.quoteIdentifier(upsertFields[i])
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", column, column))
+	}
+
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
@@ -73,6 +73,7 @@ func NewSqlDB(dbConf utils.DB, scanner scanner.Scanner, logger *zap.Logger) (*Sq
 				db.SetMaxOpenConns(50)
 				db.SetMaxIdleConns(50)
 				daoInstance := dao.NewDAO(db, dbConf, scanner)
+				daoInstance.SetLogger(logger)
 
 				return &SqlDB{db, daoInstance}, nil
 			}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	cli_orm "github.com/Alexandrhub/cli-orm-gen/cmd/cli-orm"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"go.uber.org/zap"
+)
+
+// runDiff команда diff: сравнение схемы базы данных со снимком migrate.WriteSnapshot,
+// отчет в формате JSON выводится в stdout, код выхода 1 при расхождениях и 2 при ошибке
+func runDiff(args []string) int {
+	var dbConf utils.DB
+	var snapshotPath, tables string
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.StringVar(&snapshotPath, "snapshot", "", "Path of schema snapshot JSON file")
+	flags.StringVar(&dbConf.Driver, "driver", "postgres", "Database driver: postgres, mysql or sqlite3")
+	flags.StringVar(&dbConf.Net, "net", "tcp", "Network of mysql connection")
+	flags.StringVar(&dbConf.Host, "host", "localhost", "Database host")
+	flags.StringVar(&dbConf.Port, "port", "5432", "Database port")
+	flags.StringVar(&dbConf.User, "user", "", "Database user")
+	flags.StringVar(&dbConf.Password, "password", "", "Database password")
+	flags.StringVar(&dbConf.Name, "name", "", "Database name or sqlite file")
+	flags.StringVar(&dbConf.Schema, "schema", "", "Database schema")
+	flags.IntVar(&dbConf.Timeout, "timeout", 10, "Connection timeout in seconds")
+	flags.StringVar(&tables, "tables", "", "Comma separated tables to compare, all snapshot tables by default")
+	_ = flags.Parse(args)
+
+	if snapshotPath == "" {
+		flags.PrintDefaults()
+		log.Print("empty snapshot flag")
+		return 2
+	}
+	file, err := os.Open(snapshotPath)
+	if err != nil {
+		log.Printf("open snapshot: %v", err)
+		return 2
+	}
+	defer file.Close()
+	snapshot, err := migrate.ReadSnapshot(file)
+	if err != nil {
+		log.Printf("read snapshot: %v", err)
+		return 2
+	}
+
+	var opts []migrate.Option
+	if tables != "" {
+		opts = append(opts, migrate.WithTables(strings.Split(tables, ",")...))
+	}
+	err = cli_orm.Diff(context.Background(), dbConf, migrate.SnapshotScanner(snapshot), os.Stdout, zap.NewNop(), opts...)
+	if errors.Is(err, migrate.ErrDrift) {
+		return 1
+	}
+	if err != nil {
+		log.Printf("diff: %v", err)
+		return 2
+	}
+
+	return 0
+}
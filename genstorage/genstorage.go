@@ -8,11 +8,14 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 )
@@ -27,6 +30,9 @@ const (
 func NewStorage(fileName, outputDir string) (*Storage, error) {
 	var (
 		tableName, structName string
+		relations             []string
+		indexedFields         []IndexedField
+		fieldImports          []string
 		err                   error
 	)
 
@@ -52,6 +58,14 @@ func NewStorage(fileName, outputDir string) (*Storage, error) {
 		if err != nil {
 			return nil, fmt.Errorf("GetStructName error %v", err)
 		}
+		relations, err = GetRelations(fileName, structName)
+		if err != nil {
+			return nil, fmt.Errorf("GetRelations error %v", err)
+		}
+		indexedFields, fieldImports, err = GetIndexedFields(fileName, structName)
+		if err != nil {
+			return nil, fmt.Errorf("GetIndexedFields error %v", err)
+		}
 	}
 	// выделяем имя файла
 	fileName = strings.TrimSuffix(path.Base(fileName), ".go")
@@ -77,6 +91,26 @@ func NewStorage(fileName, outputDir string) (*Storage, error) {
 		return nil, err
 	}
 
+	// собираем импорты файлов с учетом типов полей методов CountBy
+	storageImports := []string{
+		"context",
+		"fmt",
+		moduleLine + "/db/dao",
+		moduleLine + "/genstorage/models",
+		moduleLine + "/infrastructure/db/scanner",
+		moduleLine + "/utils",
+	}
+	interfaceImports := []string{
+		"context",
+		moduleLine + "/db/dao",
+		moduleLine + "/genstorage/models",
+		moduleLine + "/utils",
+	}
+	if len(indexedFields) > 0 {
+		storageImports = append(storageImports, fieldImports...)
+		interfaceImports = append(interfaceImports, fieldImports...)
+	}
+
 	// создаем шаблоны
 	storageTemplate, err := NewStorageTemplate()
 	if err != nil {
@@ -99,6 +133,10 @@ func NewStorage(fileName, outputDir string) (*Storage, error) {
 			EntityNameLowercase: tableNameLowercase,
 			EntityNameUppercase: formattedTableName,
 			EntityFirstLetter:   firstLetter,
+			Relations:           relations,
+			IndexedFields:       indexedFields,
+			StorageImports:      ImportGroups(storageImports),
+			InterfaceImports:    ImportGroups(interfaceImports),
 		},
 	}, nil
 }
@@ -115,12 +153,23 @@ func printHelp() {
 
 // TemplateData структура с данными для заполнения шаблона
 type TemplateData struct {
-	PackageName         string // название пакета
-	TableName           string // имя таблицы
-	EntityName          string // название структуры
-	EntityNameLowercase string // название структуры в нижнем регистре
-	EntityNameUppercase string // название структуры с большой буквы
-	EntityFirstLetter   string // первая буква имени структуры
+	PackageName         string         // название пакета
+	TableName           string         // имя таблицы
+	EntityName          string         // название структуры
+	EntityNameLowercase string         // название структуры в нижнем регистре
+	EntityNameUppercase string         // название структуры с большой буквы
+	EntityFirstLetter   string         // первая буква имени структуры
+	Relations           []string       // поля связей с тегом db_rel
+	IndexedFields       []IndexedField // проиндексированные поля для методов CountBy
+	StorageImports      [][]string     // группы импортов storage файла
+	InterfaceImports    [][]string     // группы импортов interface файла
+}
+
+// IndexedField проиндексированное поле структуры
+type IndexedField struct {
+	Name   string // имя поля структуры
+	Column string // имя колонки
+	Type   string // тип поля в пакете storage
 }
 
 // Storage структура с данными для работы с шаблоном
@@ -209,6 +258,150 @@ func GetStructName(fileName string) (string, error) {
 	return structName, nil
 }
 
+// GetRelations функция парсинга полей структуры со связями (тег db_rel)
+func GetRelations(fileName, structName string) ([]string, error) {
+	fs := token.NewFileSet()
+	node, err := parser.ParseFile(fs, fileName, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var relations []string
+	ast.Inspect(
+		node, func(node ast.Node) bool {
+			typeSpec, ok := node.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != structName {
+				return true
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range structType.Fields.List {
+				if field.Tag == nil || len(field.Names) == 0 {
+					continue
+				}
+				// убираем обратные кавычки и ищем тег db_rel
+				tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+				if tag.Get("db_rel") != "" {
+					relations = append(relations, field.Names[0].Name)
+				}
+			}
+			return false
+		},
+	)
+
+	return relations, nil
+}
+
+// GetIndexedFields функция парсинга полей структуры с неуникальным индексом (тег db_index),
+// кроме поля мягкого удаления deleted_at, и путей импорта пакетов их типов
+func GetIndexedFields(fileName, structName string) ([]IndexedField, []string, error) {
+	fs := token.NewFileSet()
+	node, err := parser.ParseFile(fs, fileName, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// пути импорта файла модели по имени пакета
+	imports := make(map[string]string, len(node.Imports))
+	for _, spec := range node.Imports {
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	var fields []IndexedField
+	var fieldImports []string
+	ast.Inspect(
+		node, func(node ast.Node) bool {
+			typeSpec, ok := node.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != structName {
+				return true
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range structType.Fields.List {
+				if field.Tag == nil || len(field.Names) == 0 {
+					continue
+				}
+				tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+				column := tag.Get("db")
+				index := strings.Split(tag.Get("db_index"), ",")
+				if column == "" || column == "-" || !containsString(index, "index") || containsString(index, "unique") {
+					continue
+				}
+				// поле мягкого удаления: CountBy скрывает удаленные строки, поэтому группа всегда одна
+				if column == "deleted_at" {
+					continue
+				}
+				// тип поля: локальные типы пакета моделей квалифицируются именем models
+				fieldType := types.ExprString(field.Type)
+				if ident, ok := field.Type.(*ast.Ident); ok && types.Universe.Lookup(ident.Name) == nil {
+					fieldType = "models." + ident.Name
+				}
+				ast.Inspect(field.Type, func(node ast.Node) bool {
+					if selector, ok := node.(*ast.SelectorExpr); ok {
+						if pkg, ok := selector.X.(*ast.Ident); ok && imports[pkg.Name] != "" {
+							fieldImports = append(fieldImports, imports[pkg.Name])
+						}
+					}
+					return true
+				})
+				fields = append(fields, IndexedField{Name: field.Names[0].Name, Column: column, Type: fieldType})
+			}
+			return false
+		},
+	)
+
+	return fields, fieldImports, nil
+}
+
+// containsString наличие строки в срезе
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ImportGroups разбиение путей импорта на группы стандартной библиотеки и внешних пакетов,
+// пути в группах уникальны и отсортированы
+func ImportGroups(paths []string) [][]string {
+	var std, external []string
+	seen := make(map[string]bool, len(paths))
+	for _, importPath := range paths {
+		if seen[importPath] {
+			continue
+		}
+		seen[importPath] = true
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			external = append(external, importPath)
+			continue
+		}
+		std = append(std, importPath)
+	}
+	sort.Strings(std)
+	sort.Strings(external)
+
+	var groups [][]string
+	for _, group := range [][]string{std, external} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
 // SearchFile функция поиска по файлу
 func SearchFile(confName string) ([]byte, error) {
 	wd, err := os.Getwd()
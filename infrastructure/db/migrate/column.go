@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Column колонка таблицы в базе данных
+type Column struct {
+	Name      string
+	Type      string
+	Length    int64
+	Precision int64
+	Scale     int64
+	Nullable  bool
+	Default   string
+}
+
+// SQLType тип колонки с длиной или точностью
+func (c Column) SQLType() string {
+	typ := strings.ToLower(c.Type)
+	if strings.Contains(typ, "(") {
+		return typ
+	}
+	switch {
+	case c.Length > 0:
+		return fmt.Sprintf("%s(%d)", typ, c.Length)
+	case (typ == "numeric" || typ == "decimal") && c.Precision > 0:
+		return fmt.Sprintf("%s(%d,%d)", typ, c.Precision, c.Scale)
+	}
+
+	return typ
+}
+
+// columnType разобранный тип колонки
+type columnType struct {
+	Base      string
+	Length    int64
+	Precision int64
+	Scale     int64
+}
+
+var typeRe = regexp.MustCompile(`^([a-z][a-z0-9_ ]*)(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?`)
+
+// typeAliases приведение синонимов типов к единому написанию
+var typeAliases = map[string]string{
+	"int":                         "integer",
+	"int4":                        "integer",
+	"serial":                      "integer",
+	"serial4":                     "integer",
+	"int2":                        "smallint",
+	"smallserial":                 "smallint",
+	"serial2":                     "smallint",
+	"int8":                        "bigint",
+	"bigserial":                   "bigint",
+	"serial8":                     "bigint",
+	"bool":                        "boolean",
+	"character varying":           "varchar",
+	"character":                   "char",
+	"float4":                      "real",
+	"float8":                      "double",
+	"double precision":            "double",
+	"decimal":                     "numeric",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"time without time zone":      "time",
+	"time with time zone":         "timetz",
+	"datetime":                    "timestamp",
+}
+
+// parseType разбор объявления типа колонки, например "BIGSERIAL primary key" или "varchar(255)"
+func parseType(raw string) columnType {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	for _, modifier := range []string{" primary key", " not null", " null", " unique", " unsigned", " auto_increment", " autoincrement", " default"} {
+		if i := strings.Index(raw, modifier); i > 0 {
+			raw = raw[:i]
+		}
+	}
+	var t columnType
+	matches := typeRe.FindStringSubmatch(raw)
+	if matches == nil {
+		t.Base = raw
+		return t
+	}
+	t.Base = strings.TrimSpace(matches[1])
+	if alias, ok := typeAliases[t.Base]; ok {
+		t.Base = alias
+	}
+	if matches[2] != "" {
+		n, _ := strconv.ParseInt(matches[2], 10, 64)
+		if t.Base == "numeric" {
+			t.Precision = n
+		} else {
+			t.Length = n
+		}
+	}
+	if matches[3] != "" {
+		t.Scale, _ = strconv.ParseInt(matches[3], 10, 64)
+	}
+
+	return t
+}
+
+// String тип колонки в нормализованном виде
+func (t columnType) String() string {
+	switch {
+	case t.Length > 0:
+		return fmt.Sprintf("%s(%d)", t.Base, t.Length)
+	case t.Precision > 0:
+		return fmt.Sprintf("%s(%d,%d)", t.Base, t.Precision, t.Scale)
+	}
+
+	return t.Base
+}
+
+// integerRanks порядок целочисленных типов по вместимости
+var integerRanks = map[string]int{
+	"tinyint":   1,
+	"smallint":  2,
+	"mediumint": 3,
+	"integer":   4,
+	"bigint":    5,
+}
+
+// floatRanks порядок типов с плавающей точкой по вместимости
+var floatRanks = map[string]int{
+	"real":   1,
+	"float":  1,
+	"double": 2,
+}
+
+// textTypes типы строк без ограничения длины
+var textTypes = map[string]bool{
+	"text":       true,
+	"tinytext":   true,
+	"mediumtext": true,
+	"longtext":   true,
+}
+
+// isNarrowing проверка, сужает ли новый тип колонки старый
+func isNarrowing(from, to columnType) bool {
+	if r1, ok := integerRanks[from.Base]; ok {
+		if r2, ok := integerRanks[to.Base]; ok {
+			return r2 < r1
+		}
+	}
+	if r1, ok := floatRanks[from.Base]; ok {
+		if r2, ok := floatRanks[to.Base]; ok {
+			return r2 < r1
+		}
+		_, ok = integerRanks[to.Base]
+		return ok
+	}
+	if from.Base == "numeric" {
+		if _, ok := integerRanks[to.Base]; ok {
+			return true
+		}
+		if to.Base == "numeric" {
+			return (from.Precision == 0 && to.Precision > 0) ||
+				(to.Precision > 0 && to.Precision < from.Precision) ||
+				to.Scale < from.Scale
+		}
+	}
+	isString := func(t columnType) bool {
+		return textTypes[t.Base] || t.Base == "varchar" || t.Base == "char"
+	}
+	if isString(from) && isString(to) {
+		if textTypes[to.Base] {
+			return false
+		}
+		if textTypes[from.Base] || from.Length == 0 {
+			return to.Length > 0
+		}
+		return to.Length > 0 && to.Length < from.Length
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+// Code generated by qtc from "create_index.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line create_index.qtpl:1
+package migrate
+
+//line create_index.qtpl:1
+import (
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+//line create_index.qtpl:6
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line create_index.qtpl:6
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line create_index.qtpl:6
+func StreamCreateIndex(qw422016 *qt422016.Writer, field scanner.Field, dbConf utils.DB, concurrently bool) {
+//line create_index.qtpl:6
+	qw422016.N().S(`
+    create `)
+//line create_index.qtpl:7
+	if field.Constraint.Unique {
+//line create_index.qtpl:7
+		qw422016.N().S(`unique `)
+//line create_index.qtpl:7
+	}
+//line create_index.qtpl:7
+	qw422016.N().S(`index `)
+//line create_index.qtpl:7
+	if concurrently {
+//line create_index.qtpl:7
+		qw422016.N().S(`concurrently `)
+//line create_index.qtpl:7
+	}
+//line create_index.qtpl:7
+	qw422016.E().S(IndexName(field))
+//line create_index.qtpl:7
+	qw422016.N().S(`
+     on `)
+//line create_index.qtpl:8
+	qw422016.N().S(dbConf.QualifiedName(field.Table.Name))
+//line create_index.qtpl:8
+	qw422016.N().S(` (`)
+//line create_index.qtpl:8
+	qw422016.E().S(field.Constraint.Field.Name)
+//line create_index.qtpl:8
+	qw422016.N().S(`);`)
+//line create_index.qtpl:8
+}
+
+//line create_index.qtpl:8
+func WriteCreateIndex(qq422016 qtio422016.Writer, field scanner.Field, dbConf utils.DB, concurrently bool) {
+//line create_index.qtpl:8
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line create_index.qtpl:8
+	StreamCreateIndex(qw422016, field, dbConf, concurrently)
+//line create_index.qtpl:8
+	qt422016.ReleaseWriter(qw422016)
+//line create_index.qtpl:8
+}
+
+//line create_index.qtpl:8
+func CreateIndex(field scanner.Field, dbConf utils.DB, concurrently bool) string {
+//line create_index.qtpl:8
+	qb422016 := qt422016.AcquireByteBuffer()
+//line create_index.qtpl:8
+	WriteCreateIndex(qb422016, field, dbConf, concurrently)
+//line create_index.qtpl:8
+	qs422016 := string(qb422016.B)
+//line create_index.qtpl:8
+	qt422016.ReleaseByteBuffer(qb422016)
+//line create_index.qtpl:8
+	return qs422016
+//line create_index.qtpl:8
+}
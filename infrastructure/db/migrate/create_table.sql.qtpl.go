@@ -8,9 +8,10 @@ package migrate
 import (
 	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
 	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
 
 //line create_table.sql.qtpl:6
-
+import (
 	qtio422016 "io"
 
 	qt422016 "github.com/valyala/quicktemplate"
@@ -28,7 +29,7 @@ func StreamCreateTable(qw422016 *qt422016.Writer, table scanner.Table, dbConf ut
 	qw422016.N().S(`
 create table `)
 //line create_table.sql.qtpl:7
-	qw422016.E().S(table.Name)
+	qw422016.N().S(dbConf.QualifiedName(table.Name))
 //line create_table.sql.qtpl:7
 	qw422016.N().S(`
 (
@@ -96,7 +97,7 @@ create table `)
 			qw422016.N().S(`_idx
      on `)
 //line create_table.sql.qtpl:17
-			qw422016.E().S(table.Name)
+			qw422016.N().S(dbConf.QualifiedName(table.Name))
 //line create_table.sql.qtpl:17
 			qw422016.N().S(` (`)
 //line create_table.sql.qtpl:17
@@ -0,0 +1,174 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// HistoryTable таблица истории примененных миграций данных
+const HistoryTable = "schema_migrations"
+
+// DataMigrationFunc функция миграции данных, выполняется в транзакции,
+// транзакция также передается в контексте, поэтому вызовы DAO с ctx выполняются в ней
+type DataMigrationFunc func(ctx context.Context, tx *sqlx.Tx) error
+
+// DataMigration версионированная миграция данных
+type DataMigration struct {
+	Version int64
+	Name    string
+	Up      DataMigrationFunc
+}
+
+// AppliedMigration запись истории миграций
+type AppliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// RegisterDataMigration регистрация миграции данных, миграции выполняются в порядке версий
+// после создания таблиц и добавления колонок, но до построения индексов
+func (m *Migrator) RegisterDataMigration(version int64, name string, up DataMigrationFunc) {
+	m.dataMigrations = append(m.dataMigrations, DataMigration{Version: version, Name: name, Up: up})
+}
+
+// History получение истории примененных миграций данных
+func (m *Migrator) History(ctx context.Context) ([]AppliedMigration, error) {
+	columns, err := m.columns(ctx, HistoryTable)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) < 1 {
+		return nil, nil
+	}
+
+	var history []AppliedMigration
+	queryRaw := m.builder.Select("version", "name", "applied_at").
+		From(m.dbConf.QualifiedName(HistoryTable)).
+		OrderBy("version")
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = m.db.SelectContext(ctx, &history, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	return history, nil
+}
+
+// pendingDataMigrations миграции данных, еще не записанные в историю
+func (m *Migrator) pendingDataMigrations(ctx context.Context) ([]DataMigration, error) {
+	migrations := make([]DataMigration, len(m.dataMigrations))
+	copy(migrations, m.dataMigrations)
+	sort.SliceStable(
+		migrations, func(i, j int) bool {
+			return migrations[i].Version < migrations[j].Version
+		},
+	)
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migrate: duplicate data migration version %d", migrations[i].Version)
+		}
+	}
+
+	history, err := m.History(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]struct{}, len(history))
+	for i := range history {
+		applied[history[i].Version] = struct{}{}
+	}
+
+	var pending []DataMigration
+	for i := range migrations {
+		if _, ok := applied[migrations[i].Version]; !ok {
+			pending = append(pending, migrations[i])
+		}
+	}
+
+	return pending, nil
+}
+
+// createHistoryTable создание таблицы истории миграций
+func (m *Migrator) createHistoryTable(ctx context.Context) error {
+	query := fmt.Sprintf(
+		"create table if not exists %s (version bigint primary key, name varchar(255) not null, applied_at timestamp not null)",
+		m.dbConf.QualifiedName(HistoryTable),
+	)
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s, %s", err, query)
+	}
+
+	return nil
+}
+
+// applyDataMigrations выполнение миграций данных, каждая в отдельной транзакции
+// вместе с записью в историю
+func (m *Migrator) applyDataMigrations(ctx context.Context, migrations []DataMigration) error {
+	if len(migrations) < 1 {
+		return nil
+	}
+	if err := m.createHistoryTable(ctx); err != nil {
+		return err
+	}
+
+	for i := range migrations {
+		if err := m.applyDataMigration(ctx, migrations[i]); err != nil {
+			return fmt.Errorf("migrate: data migration %d %s: %w", migrations[i].Version, migrations[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyDataMigration выполнение миграции данных в транзакции
+func (m *Migrator) applyDataMigration(ctx context.Context, migration DataMigration) error {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// запись в историю выполняется первой, чтобы параллельный мигратор ждал завершения
+	// транзакции, а при уже существующей записи пропускал примененную им миграцию
+	queryRaw := m.builder.Insert(m.dbConf.QualifiedName(HistoryTable)).
+		Columns("version", "name", "applied_at").
+		Values(migration.Version, migration.Name, time.Now().UTC())
+	if m.dbConf.Driver == "mysql" {
+		// в отличие от INSERT IGNORE пропускается только повтор ключа, прочие ошибки возвращаются;
+		// обновление без изменений дает 0 затронутых строк, если не включен clientFoundRows
+		queryRaw = queryRaw.Suffix("ON DUPLICATE KEY UPDATE name = name")
+	} else {
+		queryRaw = queryRaw.Suffix("ON CONFLICT (version) DO NOTHING")
+	}
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s, %s", err, query)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		return nil
+	}
+
+	if err = migration.Up(dao.ContextWithTx(ctx, tx), tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,335 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+
+	sq "github.com/Masterminds/squirrel"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrDrift ошибка расхождения схемы базы данных с зарегистрированными таблицами
+var ErrDrift = errors.New("migrate: schema drift detected")
+
+// DriftKind тип расхождения схемы
+type DriftKind string
+
+const (
+	DriftMissingTable  DriftKind = "missing_table"
+	DriftExtraTable    DriftKind = "extra_table"
+	DriftMissingColumn DriftKind = "missing_column"
+	DriftExtraColumn   DriftKind = "extra_column"
+	DriftTypeMismatch  DriftKind = "type_mismatch"
+	DriftMissingIndex  DriftKind = "missing_index"
+	DriftExtraIndex    DriftKind = "extra_index"
+	DriftIndexMismatch DriftKind = "index_mismatch"
+)
+
+// Drift расхождение схемы базы данных с зарегистрированной таблицей
+type Drift struct {
+	Kind     DriftKind `json:"kind"`
+	Table    string    `json:"table"`
+	Name     string    `json:"name,omitempty"`
+	Expected string    `json:"expected,omitempty"`
+	Actual   string    `json:"actual,omitempty"`
+}
+
+// String представление расхождения
+func (d Drift) String() string {
+	name := d.Table
+	if d.Name != "" {
+		name += "." + d.Name
+	}
+	if d.Expected != "" || d.Actual != "" {
+		return fmt.Sprintf("%s %s: expected %q, actual %q", d.Kind, name, d.Expected, d.Actual)
+	}
+
+	return fmt.Sprintf("%s %s", d.Kind, name)
+}
+
+// DriftReport отчет о расхождениях схемы
+type DriftReport struct {
+	Drifts []Drift `json:"drifts"`
+}
+
+// HasDrift наличие расхождений
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// WriteJSON запись отчета в формате JSON
+func (r *DriftReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// String представление отчета
+func (r *DriftReport) String() string {
+	lines := make([]string, 0, len(r.Drifts))
+	for i := range r.Drifts {
+		lines = append(lines, r.Drifts[i].String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Index индекс таблицы в базе данных
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// definition описание индекса для сравнения
+func (i Index) definition() string {
+	definition := "index (" + strings.Join(i.Columns, ",") + ")"
+	if i.Unique {
+		definition = "unique " + definition
+	}
+
+	return definition
+}
+
+// indexRow строка описания колонки индекса
+type indexRow struct {
+	Name   string `db:"index_name"`
+	Unique bool   `db:"is_unique"`
+	Column string `db:"column_name"`
+}
+
+// Drift сравнение схемы базы данных с зарегистрированными таблицами в обе стороны,
+// таблицы вне выбранных опциями WithTables не считаются лишними
+func (m *Migrator) Drift(ctx context.Context, opts ...Option) (*DriftReport, error) {
+	o := newOptions(opts...)
+	tables, err := m.selectTables(o)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := make([][]Drift, len(tables))
+	errGroup, groupCtx := errgroup.WithContext(ctx)
+	if o.concurrency > 0 {
+		errGroup.SetLimit(o.concurrency)
+	}
+	for i := range tables {
+		i := i
+		errGroup.Go(
+			func() error {
+				var tableErr error
+				drifts[i], tableErr = m.tableDrift(groupCtx, tables[i])
+				return tableErr
+			},
+		)
+	}
+	if err = errGroup.Wait(); err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{Drifts: []Drift{}}
+	for i := range drifts {
+		report.Drifts = append(report.Drifts, drifts[i]...)
+	}
+
+	if len(o.tables) < 1 {
+		extra, err := m.extraTables(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		report.Drifts = append(report.Drifts, extra...)
+	}
+
+	sort.SliceStable(
+		report.Drifts, func(i, j int) bool {
+			if report.Drifts[i].Table != report.Drifts[j].Table {
+				return report.Drifts[i].Table < report.Drifts[j].Table
+			}
+			return report.Drifts[i].Name < report.Drifts[j].Name
+		},
+	)
+
+	return report, nil
+}
+
+// tableDrift расхождения колонок и индексов таблицы
+func (m *Migrator) tableDrift(ctx context.Context, table scanner.Table) ([]Drift, error) {
+	columns, err := m.columns(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) < 1 {
+		return []Drift{{Kind: DriftMissingTable, Table: table.Name}}, nil
+	}
+
+	var drifts []Drift
+	columnsMap := make(map[string]Column, len(columns))
+	for i := range columns {
+		columnsMap[columns[i].Name] = columns[i]
+		if _, ok := table.FieldsMap[columns[i].Name]; !ok {
+			drifts = append(drifts, Drift{Kind: DriftExtraColumn, Table: table.Name, Name: columns[i].Name, Actual: columns[i].SQLType()})
+		}
+	}
+	for _, field := range table.Fields {
+		column, ok := columnsMap[field.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftMissingColumn, Table: table.Name, Name: field.Name, Expected: field.Type})
+			continue
+		}
+		expected, actual := parseType(field.Type), parseType(column.SQLType())
+		if !m.sameType(expected, actual) {
+			drifts = append(drifts, Drift{Kind: DriftTypeMismatch, Table: table.Name, Name: field.Name, Expected: expected.String(), Actual: actual.String()})
+		}
+	}
+
+	indexes, err := m.indexes(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range table.Fields {
+		if !field.Constraint.Index {
+			continue
+		}
+		name := m.storedName(IndexName(*field))
+		expected := Index{Name: name, Columns: []string{field.Name}, Unique: field.Constraint.Unique}
+		actual, ok := indexes[name]
+		switch {
+		case !ok:
+			drifts = append(drifts, Drift{Kind: DriftMissingIndex, Table: table.Name, Name: name, Expected: expected.definition()})
+		case actual.definition() != expected.definition():
+			drifts = append(drifts, Drift{Kind: DriftIndexMismatch, Table: table.Name, Name: name, Expected: expected.definition(), Actual: actual.definition()})
+		}
+		delete(indexes, name)
+	}
+	for name, index := range indexes {
+		drifts = append(drifts, Drift{Kind: DriftExtraIndex, Table: table.Name, Name: name, Actual: index.definition()})
+	}
+
+	return drifts, nil
+}
+
+// sameType сравнение типов колонок с учетом особенностей диалекта
+func (m *Migrator) sameType(expected, actual columnType) bool {
+	if m.dbConf.Driver == "mysql" {
+		if expected.Base == "boolean" {
+			expected = columnType{Base: "tinyint"}
+		}
+		if actual.Base == "tinyint" {
+			actual.Length = 0
+		}
+	}
+	if textTypes[expected.Base] || textTypes[actual.Base] {
+		return expected.Base == actual.Base
+	}
+	if expected.Base != actual.Base {
+		return false
+	}
+	if expected.Length > 0 && expected.Length != actual.Length {
+		return false
+	}
+	if expected.Precision > 0 && (expected.Precision != actual.Precision || expected.Scale != actual.Scale) {
+		return false
+	}
+
+	return true
+}
+
+// extraTables таблицы схемы, не зарегистрированные в сканере
+func (m *Migrator) extraTables(ctx context.Context, o *options) ([]Drift, error) {
+	var names []string
+	var query string
+	var args []interface{}
+	var err error
+	if m.dbConf.Driver == "sqlite3" {
+		query = fmt.Sprintf("SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'", m.dbConf.QuoteIdentifier(m.sqliteSchema()))
+	} else {
+		queryRaw := m.builder.Select("TABLE_NAME").From("INFORMATION_SCHEMA.TABLES").
+			Where(sq.Eq{"TABLE_SCHEMA": m.dbConf.SchemaName(), "TABLE_TYPE": "BASE TABLE"})
+		query, args, err = queryRaw.ToSql()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = m.db.SelectContext(ctx, &names, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	registered := make(map[string]struct{})
+	for name := range m.scanner.Tables() {
+		registered[m.storedName(name)] = struct{}{}
+	}
+	for name := range o.exclude {
+		registered[m.storedName(name)] = struct{}{}
+	}
+	registered[m.storedName(HistoryTable)] = struct{}{}
+
+	var drifts []Drift
+	for _, name := range names {
+		if _, ok := registered[name]; !ok {
+			drifts = append(drifts, Drift{Kind: DriftExtraTable, Table: name})
+		}
+	}
+
+	return drifts, nil
+}
+
+// indexes получение индексов таблицы без первичного ключа,
+// nil для драйверов без получения индексов
+func (m *Migrator) indexes(ctx context.Context, table string) (map[string]Index, error) {
+	var rows []indexRow
+	var query string
+	var args []interface{}
+	var err error
+	switch m.dbConf.Driver {
+	case "postgres":
+		queryRaw := m.builder.Select("ic.relname AS index_name", "i.indisunique AS is_unique", "a.attname AS column_name").
+			From("pg_index i").
+			Join("pg_class ic ON ic.oid = i.indexrelid").
+			Join("pg_class tc ON tc.oid = i.indrelid").
+			Join("pg_namespace n ON n.oid = tc.relnamespace").
+			Join("pg_attribute a ON a.attrelid = tc.oid AND a.attnum = ANY(i.indkey)").
+			Where(sq.Eq{"n.nspname": m.dbConf.SchemaName(), "tc.relname": m.storedName(table), "i.indisprimary": false}).
+			OrderBy("ic.relname", "array_position(i.indkey::int2[], a.attnum)")
+		query, args, err = queryRaw.ToSql()
+	case "mysql":
+		queryRaw := m.builder.Select("INDEX_NAME AS index_name", "NON_UNIQUE = 0 AS is_unique", "COLUMN_NAME AS column_name").
+			From("INFORMATION_SCHEMA.STATISTICS").
+			Where(sq.Eq{"TABLE_SCHEMA": m.dbConf.SchemaName(), "TABLE_NAME": table}).
+			Where(sq.NotEq{"INDEX_NAME": "PRIMARY"}).
+			OrderBy("INDEX_NAME", "SEQ_IN_INDEX")
+		query, args, err = queryRaw.ToSql()
+	case "sqlite3":
+		query = `SELECT il.name AS index_name, il."unique" AS is_unique, ii.name AS column_name
+			FROM pragma_index_list(?, ?) il, pragma_index_info(il.name, ?) ii
+			WHERE il.origin != 'pk' ORDER BY il.name, ii.seqno`
+		args = []interface{}{table, m.sqliteSchema(), m.sqliteSchema()}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = m.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	indexes := make(map[string]Index)
+	for i := range rows {
+		index := indexes[rows[i].Name]
+		index.Name = rows[i].Name
+		index.Unique = rows[i].Unique
+		index.Columns = append(index.Columns, rows[i].Column)
+		indexes[rows[i].Name] = index
+	}
+
+	return indexes, nil
+}
@@ -0,0 +1,385 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+// Snapshot снимок зарегистрированной схемы
+type Snapshot struct {
+	Tables []TableSnapshot `json:"tables"`
+}
+
+// TableSnapshot снимок таблицы
+type TableSnapshot struct {
+	Name        string               `json:"name"`
+	Fields      []FieldSnapshot      `json:"fields"`
+	Constraints []ConstraintSnapshot `json:"constraints,omitempty"`
+}
+
+// FieldSnapshot снимок поля таблицы
+type FieldSnapshot struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Default string   `json:"default,omitempty"`
+	Ops     []string `json:"ops,omitempty"`
+}
+
+// ConstraintSnapshot снимок индекса таблицы
+type ConstraintSnapshot struct {
+	Name         string `json:"name"`
+	Field        string `json:"field"`
+	Unique       bool   `json:"unique,omitempty"`
+	Concurrently bool   `json:"concurrently,omitempty"`
+}
+
+// sortedTables зарегистрированные таблицы, отсортированные по имени
+func sortedTables(s scanner.Scanner) []scanner.Table {
+	registered := s.Tables()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tables := make([]scanner.Table, 0, len(names))
+	for _, name := range names {
+		tables = append(tables, registered[name])
+	}
+
+	return tables
+}
+
+// ExportDDL полный скрипт создания зарегистрированных таблиц для драйвера dbConf.Driver
+func ExportDDL(s scanner.Scanner, dbConf utils.DB) string {
+	var statements []string
+	if dbConf.Schema != "" {
+		switch dbConf.Driver {
+		case "postgres":
+			statements = append(statements, fmt.Sprintf("create schema if not exists %s", dbConf.QuoteIdentifier(dbConf.Schema)))
+		case "mysql":
+			statements = append(statements, fmt.Sprintf("create database if not exists %s", dbConf.QuoteIdentifier(dbConf.Schema)))
+		}
+	}
+	for _, table := range sortedTables(s) {
+		statements = append(statements, splitStatements(CreateTable(table, dbConf))...)
+	}
+
+	return strings.Join(statements, ";\n\n") + ";\n"
+}
+
+// ExportSnapshot снимок зарегистрированных таблиц со стабильным порядком элементов
+func ExportSnapshot(s scanner.Scanner) Snapshot {
+	snapshot := Snapshot{Tables: []TableSnapshot{}}
+	for _, table := range sortedTables(s) {
+		fieldOps := make(map[string][]string, len(table.Fields))
+		for op, fields := range table.OperationFields {
+			if op == scanner.AllFields || op == scanner.Autogen {
+				continue
+			}
+			for i := range fields {
+				fieldOps[fields[i].Name] = append(fieldOps[fields[i].Name], op)
+			}
+		}
+
+		tableSnapshot := TableSnapshot{Name: table.Name, Fields: []FieldSnapshot{}}
+		for _, field := range table.Fields {
+			ops := fieldOps[field.Name]
+			sort.Strings(ops)
+			tableSnapshot.Fields = append(
+				tableSnapshot.Fields, FieldSnapshot{
+					Name:    field.Name,
+					Type:    field.Type,
+					Default: field.Default,
+					Ops:     ops,
+				},
+			)
+			if field.Constraint.Index {
+				tableSnapshot.Constraints = append(
+					tableSnapshot.Constraints, ConstraintSnapshot{
+						Name:         IndexName(*field),
+						Field:        field.Name,
+						Unique:       field.Constraint.Unique,
+						Concurrently: field.Constraint.Concurrently,
+					},
+				)
+			}
+		}
+		snapshot.Tables = append(snapshot.Tables, tableSnapshot)
+	}
+
+	return snapshot
+}
+
+// WriteSnapshot запись снимка в формате JSON
+func WriteSnapshot(w io.Writer, snapshot Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(snapshot)
+}
+
+// ReadSnapshot чтение снимка в формате JSON
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// snapshotScanner сканер таблиц снимка
+type snapshotScanner struct {
+	tables map[string]scanner.Table
+}
+
+// SnapshotScanner сканер таблиц снимка для сравнения схемы базы данных без регистрации
+// сущностей, например командой diff. Таблицы снимка не имеют сущностей, поэтому сканер
+// подходит только для Drift, но не для Migrate и DAO
+func SnapshotScanner(snapshot Snapshot) scanner.Scanner {
+	s := &snapshotScanner{tables: make(map[string]scanner.Table, len(snapshot.Tables))}
+	for _, tableSnapshot := range snapshot.Tables {
+		table := &scanner.Table{
+			Name:            tableSnapshot.Name,
+			FieldsMap:       make(map[string]*scanner.Field, len(tableSnapshot.Fields)),
+			OperationFields: make(map[string][]*scanner.Field),
+			Relations:       make(map[string]*scanner.Relation),
+		}
+		for i, fieldSnapshot := range tableSnapshot.Fields {
+			field := &scanner.Field{
+				IDx:     i,
+				Name:    fieldSnapshot.Name,
+				Type:    fieldSnapshot.Type,
+				Default: fieldSnapshot.Default,
+				Table:   table,
+			}
+			for _, op := range fieldSnapshot.Ops {
+				table.OperationFields[op] = append(table.OperationFields[op], field)
+			}
+			table.OperationFields[scanner.AllFields] = append(table.OperationFields[scanner.AllFields], field)
+			table.Fields = append(table.Fields, field)
+			table.FieldsMap[field.Name] = field
+		}
+		for _, constraint := range tableSnapshot.Constraints {
+			field, ok := table.FieldsMap[constraint.Field]
+			if !ok {
+				continue
+			}
+			field.Constraint = scanner.Constraint{Index: true, Unique: constraint.Unique, Concurrently: constraint.Concurrently, Field: field}
+			table.Constraints = append(table.Constraints, field.Constraint)
+		}
+		s.tables[table.Name] = *table
+	}
+
+	return s
+}
+
+// RegisterTable сущности не регистрируются, таблицы задаются снимком
+func (s *snapshotScanner) RegisterTable(...scanner.Tabler) {}
+
+// OperationFields получение полей для операции над таблицей, поля снимка не имеют указателей
+func (s *snapshotScanner) OperationFields(table scanner.Tabler, operation string) []*scanner.Field {
+	return s.tables[table.TableName()].OperationFields[operation]
+}
+
+// OperationFieldsName получение полей для операции над таблицей
+func (s *snapshotScanner) OperationFieldsName(tableName string, operation string) []string {
+	fields := s.tables[tableName].OperationFields[operation]
+	var fieldsName []string
+	for i := range fields {
+		fieldsName = append(fieldsName, fields[i].Name)
+	}
+
+	return fieldsName
+}
+
+// Table получение таблицы
+func (s *snapshotScanner) Table(tableName string) scanner.Table {
+	return s.tables[tableName]
+}
+
+// Tables получение таблиц
+func (s *snapshotScanner) Tables() map[string]scanner.Table {
+	return s.tables
+}
+
+// ChangeKind тип расхождения снимков
+type ChangeKind string
+
+const (
+	TableAdded        ChangeKind = "table_added"
+	TableRemoved      ChangeKind = "table_removed"
+	FieldAdded        ChangeKind = "field_added"
+	FieldRemoved      ChangeKind = "field_removed"
+	FieldChanged      ChangeKind = "field_changed"
+	ConstraintAdded   ChangeKind = "constraint_added"
+	ConstraintRemoved ChangeKind = "constraint_removed"
+	ConstraintChanged ChangeKind = "constraint_changed"
+)
+
+// SnapshotChange расхождение между снимками
+type SnapshotChange struct {
+	Kind  ChangeKind `json:"kind"`
+	Table string     `json:"table"`
+	Name  string     `json:"name,omitempty"`
+	Old   string     `json:"old,omitempty"`
+	New   string     `json:"new,omitempty"`
+}
+
+// String представление расхождения
+func (c SnapshotChange) String() string {
+	name := c.Table
+	if c.Name != "" {
+		name += "." + c.Name
+	}
+	if c.Old != "" || c.New != "" {
+		return fmt.Sprintf("%s %s: %s -> %s", c.Kind, name, c.Old, c.New)
+	}
+
+	return fmt.Sprintf("%s %s", c.Kind, name)
+}
+
+// CompareSnapshots расхождения схемы new относительно old
+func CompareSnapshots(old, new Snapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	oldTables := make(map[string]TableSnapshot, len(old.Tables))
+	for _, table := range old.Tables {
+		oldTables[table.Name] = table
+	}
+	newTables := make(map[string]TableSnapshot, len(new.Tables))
+	for _, table := range new.Tables {
+		newTables[table.Name] = table
+	}
+
+	for _, table := range old.Tables {
+		if _, ok := newTables[table.Name]; !ok {
+			changes = append(changes, SnapshotChange{Kind: TableRemoved, Table: table.Name})
+		}
+	}
+	for _, table := range new.Tables {
+		oldTable, ok := oldTables[table.Name]
+		if !ok {
+			changes = append(changes, SnapshotChange{Kind: TableAdded, Table: table.Name})
+			continue
+		}
+		changes = append(changes, compareFields(oldTable, table)...)
+		changes = append(changes, compareConstraints(oldTable, table)...)
+	}
+
+	sort.SliceStable(
+		changes, func(i, j int) bool {
+			if changes[i].Table != changes[j].Table {
+				return changes[i].Table < changes[j].Table
+			}
+			return changes[i].Name < changes[j].Name
+		},
+	)
+
+	return changes
+}
+
+// compareFields расхождения полей таблицы
+func compareFields(old, new TableSnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	oldFields := make(map[string]FieldSnapshot, len(old.Fields))
+	for _, field := range old.Fields {
+		oldFields[field.Name] = field
+	}
+	newFields := make(map[string]FieldSnapshot, len(new.Fields))
+	for _, field := range new.Fields {
+		newFields[field.Name] = field
+	}
+
+	for _, field := range old.Fields {
+		if _, ok := newFields[field.Name]; !ok {
+			changes = append(changes, SnapshotChange{Kind: FieldRemoved, Table: new.Name, Name: field.Name, Old: field.definition()})
+		}
+	}
+	for _, field := range new.Fields {
+		oldField, ok := oldFields[field.Name]
+		switch {
+		case !ok:
+			changes = append(changes, SnapshotChange{Kind: FieldAdded, Table: new.Name, Name: field.Name, New: field.definition()})
+		case oldField.definition() != field.definition():
+			changes = append(
+				changes, SnapshotChange{
+					Kind:  FieldChanged,
+					Table: new.Name,
+					Name:  field.Name,
+					Old:   oldField.definition(),
+					New:   field.definition(),
+				},
+			)
+		}
+	}
+
+	return changes
+}
+
+// compareConstraints расхождения индексов таблицы
+func compareConstraints(old, new TableSnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+	oldConstraints := make(map[string]ConstraintSnapshot, len(old.Constraints))
+	for _, constraint := range old.Constraints {
+		oldConstraints[constraint.Name] = constraint
+	}
+	newConstraints := make(map[string]ConstraintSnapshot, len(new.Constraints))
+	for _, constraint := range new.Constraints {
+		newConstraints[constraint.Name] = constraint
+	}
+
+	for _, constraint := range old.Constraints {
+		if _, ok := newConstraints[constraint.Name]; !ok {
+			changes = append(changes, SnapshotChange{Kind: ConstraintRemoved, Table: new.Name, Name: constraint.Name, Old: constraint.definition()})
+		}
+	}
+	for _, constraint := range new.Constraints {
+		oldConstraint, ok := oldConstraints[constraint.Name]
+		switch {
+		case !ok:
+			changes = append(changes, SnapshotChange{Kind: ConstraintAdded, Table: new.Name, Name: constraint.Name, New: constraint.definition()})
+		case oldConstraint != constraint:
+			changes = append(
+				changes, SnapshotChange{
+					Kind:  ConstraintChanged,
+					Table: new.Name,
+					Name:  constraint.Name,
+					Old:   oldConstraint.definition(),
+					New:   constraint.definition(),
+				},
+			)
+		}
+	}
+
+	return changes
+}
+
+// definition описание поля для сравнения
+func (f FieldSnapshot) definition() string {
+	definition := strings.TrimSpace(f.Type + " " + f.Default)
+	if len(f.Ops) > 0 {
+		definition += " ops(" + strings.Join(f.Ops, ",") + ")"
+	}
+
+	return definition
+}
+
+// definition описание индекса для сравнения
+func (c ConstraintSnapshot) definition() string {
+	definition := "index (" + c.Field + ")"
+	if c.Unique {
+		definition = "unique " + definition
+	}
+	if c.Concurrently {
+		definition += " concurrently"
+	}
+
+	return definition
+}
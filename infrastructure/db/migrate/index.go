@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// IndexName имя индекса по колонке таблицы
+func IndexName(field scanner.Field) string {
+	return fmt.Sprintf("%s_%s_idx", field.Table.Name, field.Name)
+}
+
+// concurrentIndex построение индекса поля без блокировки записи
+func (m *Migrator) concurrentIndex(field *scanner.Field, o *options) bool {
+	return m.dbConf.Driver == "postgres" && (o.concurrentIndexes || field.Constraint.Concurrently)
+}
+
+// invalidIndexes получение невалидных индексов таблицы postgres,
+// оставшихся после неудачного построения с CONCURRENTLY
+func (m *Migrator) invalidIndexes(ctx context.Context, table string) (map[string]struct{}, error) {
+	invalid := make(map[string]struct{})
+	if m.dbConf.Driver != "postgres" {
+		return invalid, nil
+	}
+
+	var names []string
+	queryRaw := m.builder.Select("ic.relname").From("pg_index i").
+		Join("pg_class ic ON ic.oid = i.indexrelid").
+		Join("pg_class tc ON tc.oid = i.indrelid").
+		Join("pg_namespace n ON n.oid = tc.relnamespace").
+		Where(sq.Eq{"n.nspname": m.dbConf.SchemaName(), "tc.relname": m.storedName(table), "i.indisvalid": false})
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = m.db.SelectContext(ctx, &names, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+	for i := range names {
+		invalid[names[i]] = struct{}{}
+	}
+
+	return invalid, nil
+}
+
+// dropIndexConcurrently удаление индекса postgres без блокировки записи
+func (m *Migrator) dropIndexConcurrently(ctx context.Context, index string) error {
+	query := fmt.Sprintf("drop index concurrently if exists %s", m.dbConf.QualifiedName(index))
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s, %s", err, query)
+	}
+
+	return nil
+}
+
+// createIndexConcurrently построение индекса с CONCURRENTLY вне транзакции,
+// невалидный индекс от прошлой попытки удаляется перед построением,
+// при ошибке построения оставшийся невалидный индекс удаляется
+func (m *Migrator) createIndexConcurrently(ctx context.Context, table, index, statement string) error {
+	invalid, err := m.invalidIndexes(ctx, table)
+	if err != nil {
+		return err
+	}
+	if _, ok := invalid[m.storedName(index)]; ok {
+		if err = m.dropIndexConcurrently(ctx, index); err != nil {
+			return err
+		}
+	}
+
+	if _, err = m.db.ExecContext(ctx, statement); err != nil {
+		buildErr := fmt.Errorf("%s, %s", err, statement)
+		// контекст мог быть отменен, очистка выполняется независимо от него
+		cleanupCtx := context.WithoutCancel(ctx)
+		invalid, err = m.invalidIndexes(cleanupCtx, table)
+		if err != nil {
+			return buildErr
+		}
+		if _, ok := invalid[m.storedName(index)]; ok {
+			_ = m.dropIndexConcurrently(cleanupCtx, index)
+		}
+		return buildErr
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// columnRow строка INFORMATION_SCHEMA.COLUMNS
+type columnRow struct {
+	Name      string         `db:"column_name"`
+	Type      string         `db:"data_type"`
+	Length    sql.NullInt64  `db:"char_length"`
+	Precision sql.NullInt64  `db:"num_precision"`
+	Scale     sql.NullInt64  `db:"num_scale"`
+	Nullable  string         `db:"is_nullable"`
+	Default   sql.NullString `db:"column_default"`
+}
+
+// sqliteColumnRow строка pragma_table_info
+type sqliteColumnRow struct {
+	Name    string         `db:"name"`
+	Type    string         `db:"type"`
+	NotNull bool           `db:"notnull"`
+	Default sql.NullString `db:"dflt_value"`
+}
+
+// storedName имя таблицы или индекса в каталоге базы данных,
+// postgres приводит имена без кавычек к нижнему регистру
+func (m *Migrator) storedName(name string) string {
+	if m.dbConf.Driver == "postgres" {
+		return strings.ToLower(name)
+	}
+
+	return name
+}
+
+// columns получение колонок таблицы из базы данных, пустой результат означает отсутствие таблицы
+func (m *Migrator) columns(ctx context.Context, table string) ([]Column, error) {
+	if m.dbConf.Driver == "sqlite3" {
+		return m.sqliteColumns(ctx, table)
+	}
+
+	var rows []columnRow
+	queryRaw := m.builder.Select(
+		"COLUMN_NAME AS column_name",
+		"DATA_TYPE AS data_type",
+		"CHARACTER_MAXIMUM_LENGTH AS char_length",
+		"NUMERIC_PRECISION AS num_precision",
+		"NUMERIC_SCALE AS num_scale",
+		"IS_NULLABLE AS is_nullable",
+		"COLUMN_DEFAULT AS column_default",
+	).From("INFORMATION_SCHEMA.COLUMNS")
+	queryRaw = queryRaw.Where(sq.Eq{"TABLE_SCHEMA": m.dbConf.SchemaName(), "TABLE_NAME": m.storedName(table)})
+	queryRaw = queryRaw.OrderBy("ORDINAL_POSITION")
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	err = m.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	columns := make([]Column, 0, len(rows))
+	for i := range rows {
+		columns = append(
+			columns, Column{
+				Name:      rows[i].Name,
+				Type:      rows[i].Type,
+				Length:    rows[i].Length.Int64,
+				Precision: rows[i].Precision.Int64,
+				Scale:     rows[i].Scale.Int64,
+				Nullable:  rows[i].Nullable == "YES",
+				Default:   rows[i].Default.String,
+			},
+		)
+	}
+
+	return columns, nil
+}
+
+// sqliteSchema имя присоединенной базы данных sqlite, в которой размещаются таблицы
+func (m *Migrator) sqliteSchema() string {
+	if m.dbConf.Schema != "" {
+		return m.dbConf.Schema
+	}
+
+	return "main"
+}
+
+// sqliteColumns получение колонок таблицы sqlite
+func (m *Migrator) sqliteColumns(ctx context.Context, table string) ([]Column, error) {
+	var rows []sqliteColumnRow
+	query := `SELECT name, type, "notnull", dflt_value FROM pragma_table_info(?)`
+	args := []interface{}{table}
+	if m.dbConf.Schema != "" {
+		query = `SELECT name, type, "notnull", dflt_value FROM pragma_table_info(?, ?)`
+		args = append(args, m.dbConf.Schema)
+	}
+	err := m.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s, %s", err, query)
+	}
+
+	columns := make([]Column, 0, len(rows))
+	for i := range rows {
+		columns = append(
+			columns, Column{
+				Name:     rows[i].Name,
+				Type:     rows[i].Type,
+				Nullable: !rows[i].NotNull,
+				Default:  rows[i].Default.String,
+			},
+		)
+	}
+
+	return columns, nil
+}
+
+// estimateRows оценка количества строк таблицы, -1 если оценка недоступна
+func (m *Migrator) estimateRows(ctx context.Context, table string) (int64, error) {
+	if m.dbConf.Driver != "postgres" {
+		return -1, nil
+	}
+
+	var rows []int64
+	queryRaw := m.builder.Select("c.reltuples::bigint").From("pg_class c").
+		Join("pg_namespace n ON n.oid = c.relnamespace").
+		Where(sq.Eq{"n.nspname": m.dbConf.SchemaName(), "c.relname": m.storedName(table)})
+	query, args, err := queryRaw.ToSql()
+	if err != nil {
+		return -1, err
+	}
+	err = m.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return -1, fmt.Errorf("%s, %s", err, query)
+	}
+	if len(rows) < 1 || rows[0] < 0 {
+		return -1, nil
+	}
+
+	return rows[0], nil
+}
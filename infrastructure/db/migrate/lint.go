@@ -0,0 +1,176 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Level уровень замечания линтера
+type Level string
+
+const (
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// правила линтера
+const (
+	RuleNotNullWithoutDefault = "not-null-without-default"
+	RuleNonConcurrentIndex    = "non-concurrent-index"
+	RuleTypeNarrowing         = "type-narrowing"
+	RuleDropColumn            = "drop-column"
+)
+
+// defaultLargeTableRows количество строк, начиная с которого таблица postgres считается большой
+const defaultLargeTableRows = 100000
+
+// Issue замечание линтера
+type Issue struct {
+	Level     Level
+	Rule      string
+	Table     string
+	Statement string
+	Message   string
+}
+
+// String представление замечания
+func (i Issue) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", i.Level, i.Rule, i.Table, i.Message)
+}
+
+// Report отчет линтера
+type Report struct {
+	Issues []Issue
+}
+
+// HasErrors наличие замечаний уровня error
+func (r Report) HasErrors() bool {
+	for i := range r.Issues {
+		if r.Issues[i].Level == LevelError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String представление отчета
+func (r Report) String() string {
+	lines := make([]string, 0, len(r.Issues))
+	for i := range r.Issues {
+		lines = append(lines, r.Issues[i].String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// LintError ошибка строгого режима, план содержит опасные операции
+type LintError struct {
+	Report Report
+}
+
+// Error имплементирует error
+func (e *LintError) Error() string {
+	return fmt.Sprintf("migrate: plan contains dangerous operations:\n%s", e.Report)
+}
+
+var (
+	addColumnRe   = regexp.MustCompile(`(?is)^alter\s+table\s+(\S+)\s+add\s+(?:column\s+)?(?:if\s+not\s+exists\s+)?(\S+)\s+(.*)$`)
+	createIndexRe = regexp.MustCompile(`(?is)^create\s+(?:unique\s+)?index\s+(concurrently\s+)?`)
+	dropColumnRe  = regexp.MustCompile(`(?is)^alter\s+table\s+(\S+)\s+drop\s+(?:column\s+)?(?:if\s+exists\s+)?(\S+)`)
+	alterTypeRe   = regexp.MustCompile(`(?is)^alter\s+table\s+(\S+)\s+(?:alter\s+(?:column\s+)?(\S+)\s+(?:set\s+data\s+)?type\s+|modify\s+(?:column\s+)?(\S+)\s+)(.+?)(?:\s+using\s+.*)?$`)
+	notNullRe     = regexp.MustCompile(`(?i)\bnot\s+null\b|\bprimary\s+key\b`)
+	defaultRe     = regexp.MustCompile(`(?i)\bdefault\b`)
+	serialRe      = regexp.MustCompile(`(?i)\b(?:small|big)?serial\d?\b|\bauto_?increment\b`)
+)
+
+// tableKeywords ключевые слова, которые после add/drop означают не колонку
+var tableKeywords = map[string]bool{
+	"constraint": true,
+	"index":      true,
+	"key":        true,
+	"primary":    true,
+	"foreign":    true,
+	"unique":     true,
+	"check":      true,
+}
+
+// Lint анализ плана на операции, опасные для миграции без простоя.
+// Удаление колонок и сужение типов планировщик не создает, эти правила относятся
+// к шагам StepCustom и другим шагам, добавленным в план вручную; для проверки сужения
+// в Step.Columns передаются текущие колонки таблицы, без них выдается предупреждение
+func (p *Plan) Lint() Report {
+	var report Report
+	largeTableRows := p.largeTableRows
+	if largeTableRows <= 0 {
+		largeTableRows = defaultLargeTableRows
+	}
+
+	for _, step := range p.Steps {
+		for _, statement := range step.Statements {
+			issue := Issue{Table: step.Table, Statement: statement}
+
+			matches := addColumnRe.FindStringSubmatch(statement)
+			if matches != nil && step.Kind != StepCreateTable && !tableKeywords[strings.ToLower(matches[2])] {
+				definition := matches[3]
+				if notNullRe.MatchString(definition) && !defaultRe.MatchString(definition) && !serialRe.MatchString(definition) {
+					issue.Level = LevelError
+					issue.Rule = RuleNotNullWithoutDefault
+					issue.Message = fmt.Sprintf("column %s is added as NOT NULL without a default value", matches[2])
+					report.Issues = append(report.Issues, issue)
+				}
+			}
+
+			if matches = createIndexRe.FindStringSubmatch(statement); matches != nil {
+				if p.Driver == "postgres" && matches[1] == "" && step.Kind != StepCreateTable && step.Rows >= largeTableRows {
+					issue.Level = LevelError
+					issue.Rule = RuleNonConcurrentIndex
+					issue.Message = fmt.Sprintf("index is built without CONCURRENTLY on a table with ~%d rows", step.Rows)
+					report.Issues = append(report.Issues, issue)
+				}
+			}
+
+			if matches = dropColumnRe.FindStringSubmatch(statement); matches != nil && !tableKeywords[strings.ToLower(matches[2])] {
+				issue.Level = LevelError
+				issue.Rule = RuleDropColumn
+				issue.Message = fmt.Sprintf("column %s is dropped", matches[2])
+				report.Issues = append(report.Issues, issue)
+			}
+
+			if matches = alterTypeRe.FindStringSubmatch(statement); matches != nil {
+				columnName := matches[2]
+				if columnName == "" {
+					columnName = matches[3]
+				}
+				to := parseType(matches[4])
+				column, ok := step.Columns[columnName]
+				switch {
+				case !ok:
+					issue.Level = LevelWarning
+					issue.Rule = RuleTypeNarrowing
+					issue.Message = fmt.Sprintf("type of column %s is changed to %s, current type is unknown", columnName, to)
+					report.Issues = append(report.Issues, issue)
+				case isNarrowing(parseType(column.SQLType()), to):
+					issue.Level = LevelError
+					issue.Rule = RuleTypeNarrowing
+					issue.Message = fmt.Sprintf("type of column %s is narrowed from %s to %s", columnName, parseType(column.SQLType()), to)
+					report.Issues = append(report.Issues, issue)
+				}
+			}
+		}
+	}
+
+	return report
+}
+
+// Lint построение плана миграции и его анализ линтером
+func (m *Migrator) Lint(ctx context.Context, opts ...Option) (Report, error) {
+	plan, err := m.Plan(ctx, opts...)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return plan.Lint(), nil
+}
@@ -3,7 +3,7 @@ package migrate
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sort"
 
 	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
 	"github.com/Alexandrhub/cli-orm-gen/utils"
@@ -22,111 +22,175 @@ type Migrator struct {
 	db      *sqlx.DB
 	dbConf  utils.DB
 	scanner scanner.Scanner
+	builder sq.StatementBuilderType
+
+	dataMigrations []DataMigration
 }
 
 // NewMigrator конструктор
 func NewMigrator(db *sqlx.DB, dbConf utils.DB, scanner scanner.Scanner) *Migrator {
-	return &Migrator{db: db, dbConf: dbConf, scanner: scanner}
-}
-
-// Migrate миграция для всех таблиц
-func (m *Migrator) Migrate() error {
-	tables := m.scanner.Tables()
-	var err error
-	var query string
-	var args []interface{}
 	var builder sq.StatementBuilderType
-	var schema string
-	if m.dbConf.Driver == "mysql" {
+	if dbConf.Driver == "mysql" {
 		builder = sq.StatementBuilder.PlaceholderFormat(sq.Question)
-		schema = m.dbConf.Name
 	}
-	if m.dbConf.Driver == "sqlite3" {
+	if dbConf.Driver == "sqlite3" {
 		builder = sq.StatementBuilder.PlaceholderFormat(sq.Question)
-		schema = m.dbConf.Name
 	}
-	if m.dbConf.Driver == "postgres" {
+	if dbConf.Driver == "postgres" {
 		builder = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-		schema = "public"
-	}
-	errGroup, ctx := errgroup.WithContext(context.Background())
-	for name := range tables {
-		table := tables[name]
-		var tableFields []string
-		queryRaw := builder.Select("COLUMN_NAME").From("INFORMATION_SCHEMA.COLUMNS")
-		queryRaw = queryRaw.Where(sq.Eq{"TABLE_SCHEMA": schema, "TABLE_NAME": table.Name})
-		query, args, err = queryRaw.ToSql()
-		if m.dbConf.Driver != "sqlite3" {
-			err = m.db.Select(&tableFields, query, args...)
-			if err != nil {
-				return fmt.Errorf("%s, %s", err, query)
-			}
+	}
+
+	return &Migrator{db: db, dbConf: dbConf, scanner: scanner, builder: builder}
+}
+
+// Migrate миграция зарегистрированных таблиц, прерывается при отмене контекста
+func (m *Migrator) Migrate(ctx context.Context, opts ...Option) error {
+	o := newOptions(opts...)
+	plan, err := m.plan(ctx, o)
+	if err != nil {
+		return err
+	}
+	if o.strict {
+		if report := plan.Lint(); report.HasErrors() {
+			return &LintError{Report: report}
 		}
-		tableFieldsMap := make(map[string]string, len(tableFields))
-		for i := range tableFields {
-			tableFieldsMap[tableFields[i]] = tableFields[i]
+	}
+
+	if err = m.createSchema(ctx); err != nil {
+		return err
+	}
+
+	// миграции данных заполняют добавленные колонки до построения индексов по ним
+	var schemaSteps, indexSteps []Step
+	for _, step := range plan.Steps {
+		if step.indexStep() {
+			indexSteps = append(indexSteps, step)
+			continue
 		}
-		if len(tableFields) < 1 {
-			errGroup.Go(
-				func() error {
-					createQuery := CreateTable(table, m.dbConf)
-					queries := strings.Split(createQuery, ";")
-					for i := range queries {
-						queries[i] = strings.TrimSpace(queries[i])
-						if queries[i] == "" {
-							continue
-						}
-						_, err = m.db.ExecContext(ctx, queries[i])
-						if err != nil {
-							if _, ok := err.(sqlite3.Error); ok {
-								continue
-							}
-							if v, ok := err.(*pq.Error); ok {
-								if v.Code != "42P07" {
-									return fmt.Errorf("%s, %s", err, queries[i])
-								}
-							} else {
-								return fmt.Errorf("%s, %s", err, queries[i])
-							}
-						}
-					}
-					return nil
-				},
-			)
+		schemaSteps = append(schemaSteps, step)
+	}
+	if err = m.apply(ctx, schemaSteps, o); err != nil {
+		return err
+	}
+	if err = m.applyDataMigrations(ctx, plan.DataMigrations); err != nil {
+		return err
+	}
+
+	return m.apply(ctx, indexSteps, o)
+}
+
+// selectTables выбор таблиц для миграции с учетом опций
+func (m *Migrator) selectTables(o *options) ([]scanner.Table, error) {
+	registered := m.scanner.Tables()
+	names := o.tables
+	if len(names) < 1 {
+		names = make([]string, 0, len(registered))
+		for name := range registered {
+			names = append(names, name)
 		}
-		if len(tableFields) > 0 {
-			errGroup.Go(
-				func() error {
-					entityFields := m.scanner.OperationFieldsName(table.Name, scanner.AllFields)
-					diff := make(map[string]*scanner.Field, len(entityFields))
-					for i := range entityFields {
-						if _, ok := tableFieldsMap[entityFields[i]]; !ok {
-							diff[entityFields[i]] = table.FieldsMap[entityFields[i]]
-						}
-					}
-					for fieldName := range diff {
-						if fieldName == "" {
-							continue
-						}
-						alterQuery := AlterTable(*diff[fieldName])
-						queries := strings.Split(alterQuery, ";")
-						for i := range queries {
-							queries[i] = strings.TrimSpace(queries[i])
-							if queries[i] == "" {
-								continue
-							}
-							_, err = m.db.Queryx(queries[i])
-							if err != nil {
-								return fmt.Errorf("%s, %s", err, queries[i])
-							}
-						}
-					}
+	}
+	sort.Strings(names)
+
+	tables := make([]scanner.Table, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		table, ok := registered[name]
+		if !ok {
+			return nil, fmt.Errorf("migrate: table %s is not registered", name)
+		}
+		if _, ok = o.exclude[name]; ok {
+			continue
+		}
+		if _, ok = seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// createSchema создание явно заданной схемы, если она еще не существует
+func (m *Migrator) createSchema(ctx context.Context) error {
+	if m.dbConf.Schema == "" {
+		return nil
+	}
+	var query string
+	switch m.dbConf.Driver {
+	case "postgres":
+		query = fmt.Sprintf("create schema if not exists %s", m.dbConf.QuoteIdentifier(m.dbConf.Schema))
+	case "mysql":
+		query = fmt.Sprintf("create database if not exists %s", m.dbConf.QuoteIdentifier(m.dbConf.Schema))
+	default:
+		return nil
+	}
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%s, %s", err, query)
+	}
+
+	return nil
+}
 
-					return nil
-				},
-			)
+// apply применение шагов плана, таблицы мигрируются параллельно, шаги одной таблицы последовательно
+func (m *Migrator) apply(ctx context.Context, steps []Step, o *options) error {
+	var tables []string
+	tableSteps := make(map[string][]Step)
+	for _, step := range steps {
+		if _, ok := tableSteps[step.Table]; !ok {
+			tables = append(tables, step.Table)
 		}
+		tableSteps[step.Table] = append(tableSteps[step.Table], step)
+	}
+
+	errGroup, ctx := errgroup.WithContext(ctx)
+	if o.concurrency > 0 {
+		errGroup.SetLimit(o.concurrency)
+	}
+	for i := range tables {
+		steps := tableSteps[tables[i]]
+		errGroup.Go(
+			func() error {
+				for _, step := range steps {
+					if err := m.applyStep(ctx, step); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)
 	}
 
 	return errGroup.Wait()
 }
+
+// applyStep выполнение запросов шага миграции
+func (m *Migrator) applyStep(ctx context.Context, step Step) error {
+	for _, statement := range step.Statements {
+		if matches := createIndexRe.FindStringSubmatch(statement); matches != nil && matches[1] != "" && step.Index != "" {
+			if err := m.createIndexConcurrently(ctx, step.Table, step.Index, statement); err != nil {
+				return err
+			}
+			continue
+		}
+		_, err := m.db.ExecContext(ctx, statement)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if step.Kind == StepCreateTable {
+			if _, ok := err.(sqlite3.Error); ok {
+				continue
+			}
+			if v, ok := err.(*pq.Error); ok && v.Code == "42P07" {
+				continue
+			}
+		}
+
+		return fmt.Errorf("%s, %s", err, statement)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package migrate
+
+// Option опция миграции
+type Option func(*options)
+
+// options параметры запуска миграции
+type options struct {
+	tables         []string
+	exclude        map[string]struct{}
+	concurrency    int
+	strict         bool
+	largeTableRows int64
+
+	concurrentIndexes bool
+	skipData          bool
+}
+
+// WithTables мигрировать только указанные таблицы
+func WithTables(tables ...string) Option {
+	return func(o *options) {
+		o.tables = append(o.tables, tables...)
+	}
+}
+
+// WithExcludeTables исключить указанные таблицы из миграции
+func WithExcludeTables(tables ...string) Option {
+	return func(o *options) {
+		for i := range tables {
+			o.exclude[tables[i]] = struct{}{}
+		}
+	}
+}
+
+// WithConcurrency ограничение количества одновременно мигрируемых таблиц,
+// значение меньше 1 снимает ограничение
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithStrict строгий режим: миграция не применяется,
+// если линтер нашел в плане опасные операции
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithLargeTableRows количество строк, начиная с которого таблица считается большой
+func WithLargeTableRows(rows int64) Option {
+	return func(o *options) {
+		o.largeTableRows = rows
+	}
+}
+
+// WithConcurrentIndexes построение индексов существующих таблиц postgres
+// с CREATE INDEX CONCURRENTLY вне транзакции
+func WithConcurrentIndexes() Option {
+	return func(o *options) {
+		o.concurrentIndexes = true
+	}
+}
+
+// WithoutDataMigrations пропуск миграций данных
+func WithoutDataMigrations() Option {
+	return func(o *options) {
+		o.skipData = true
+	}
+}
+
+// newOptions применение опций
+func newOptions(opts ...Option) *options {
+	o := &options{exclude: make(map[string]struct{})}
+	for i := range opts {
+		opts[i](o)
+	}
+
+	return o
+}
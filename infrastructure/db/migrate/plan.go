@@ -0,0 +1,212 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// StepKind тип шага миграции
+type StepKind string
+
+const (
+	StepCreateTable  StepKind = "create_table"
+	StepAddColumn    StepKind = "add_column"
+	StepCreateIndex  StepKind = "create_index"
+	StepRebuildIndex StepKind = "rebuild_index"
+	// StepCustom шаг с запросами, написанными вручную или полученными извне,
+	// планировщик такие шаги не создает, но Lint проверяет их наравне с остальными
+	StepCustom StepKind = "custom"
+)
+
+// Step шаг плана миграции
+type Step struct {
+	Kind       StepKind
+	Table      string
+	Field      *scanner.Field
+	Statements []string
+	// Columns колонки таблицы в базе данных на момент планирования
+	Columns map[string]Column
+	// Rows оценка количества строк таблицы, -1 если оценка недоступна
+	Rows int64
+	// Index имя индекса, который строится с CONCURRENTLY
+	Index string
+}
+
+// Plan план миграции
+type Plan struct {
+	Driver string
+	Steps  []Step
+	// DataMigrations миграции данных, ожидающие применения: выполняются после создания таблиц
+	// и добавления колонок, но до построения индексов, чтобы заполнить новые колонки
+	DataMigrations []DataMigration
+
+	largeTableRows int64
+}
+
+// Plan построение плана миграции без изменения базы данных
+func (m *Migrator) Plan(ctx context.Context, opts ...Option) (*Plan, error) {
+	return m.plan(ctx, newOptions(opts...))
+}
+
+// plan построение плана миграции
+func (m *Migrator) plan(ctx context.Context, o *options) (*Plan, error) {
+	tables, err := m.selectTables(o)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([][]Step, len(tables))
+	errGroup, groupCtx := errgroup.WithContext(ctx)
+	if o.concurrency > 0 {
+		errGroup.SetLimit(o.concurrency)
+	}
+	for i := range tables {
+		i := i
+		errGroup.Go(
+			func() error {
+				var tableErr error
+				steps[i], tableErr = m.planTable(groupCtx, tables[i], o)
+				return tableErr
+			},
+		)
+	}
+	if err = errGroup.Wait(); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Driver: m.dbConf.Driver, largeTableRows: o.largeTableRows}
+	for i := range steps {
+		plan.Steps = append(plan.Steps, steps[i]...)
+	}
+	if !o.skipData {
+		plan.DataMigrations, err = m.pendingDataMigrations(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// planTable шаги миграции таблицы: создание, добавление недостающих колонок,
+// создание отсутствующих и перестроение невалидных индексов
+func (m *Migrator) planTable(ctx context.Context, table scanner.Table, o *options) ([]Step, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	columns, err := m.columns(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) < 1 {
+		return []Step{
+			{
+				Kind:       StepCreateTable,
+				Table:      table.Name,
+				Statements: splitStatements(CreateTable(table, m.dbConf)),
+			},
+		}, nil
+	}
+
+	rows, err := m.estimateRows(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := m.indexes(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	invalid, err := m.invalidIndexes(ctx, table.Name)
+	if err != nil {
+		return nil, err
+	}
+	columnsMap := make(map[string]Column, len(columns))
+	for i := range columns {
+		columnsMap[columns[i].Name] = columns[i]
+	}
+
+	var steps []Step
+	for _, field := range table.Fields {
+		if field.Name == "" {
+			continue
+		}
+		concurrently := field.Constraint.Index && m.concurrentIndex(field, o)
+		step := Step{
+			Kind:    StepAddColumn,
+			Table:   table.Name,
+			Field:   field,
+			Columns: columnsMap,
+			Rows:    rows,
+		}
+		if concurrently {
+			step.Index = IndexName(*field)
+		}
+		if _, ok := columnsMap[field.Name]; !ok {
+			indexStep := step
+			indexStep.Kind = StepCreateIndex
+			step.Statements, indexStep.Statements = splitIndexes(splitStatements(AlterTable(*field, m.dbConf, concurrently)))
+			steps = append(steps, step)
+			if len(indexStep.Statements) > 0 {
+				steps = append(steps, indexStep)
+			}
+			continue
+		}
+		if !field.Constraint.Index {
+			continue
+		}
+		name := m.storedName(IndexName(*field))
+		if _, ok := invalid[name]; ok {
+			step.Kind = StepRebuildIndex
+			step.Index = IndexName(*field)
+			step.Statements = splitStatements(CreateIndex(*field, m.dbConf, true))
+			steps = append(steps, step)
+			continue
+		}
+		// индекс мог не создаться или быть удален после неудачного построения
+		if _, ok := indexes[name]; !ok && indexes != nil {
+			step.Kind = StepCreateIndex
+			step.Statements = splitStatements(CreateIndex(*field, m.dbConf, concurrently))
+			steps = append(steps, step)
+		}
+	}
+
+	return steps, nil
+}
+
+// splitStatements разбиение скрипта на отдельные запросы
+func splitStatements(script string) []string {
+	var statements []string
+	for _, statement := range strings.Split(script, ";") {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements
+}
+
+// splitIndexes отделение запросов создания индексов от остальных запросов шага
+func splitIndexes(statements []string) ([]string, []string) {
+	var other, indexes []string
+	for _, statement := range statements {
+		if createIndexRe.MatchString(statement) {
+			indexes = append(indexes, statement)
+			continue
+		}
+		other = append(other, statement)
+	}
+
+	return other, indexes
+}
+
+// indexStep шаг создания или перестроения индекса существующей таблицы
+func (s Step) indexStep() bool {
+	return s.Kind == StepCreateIndex || s.Kind == StepRebuildIndex
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type memberDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Code string `db:"code" db_type:"varchar(36)" db_default:"not null default 0" db_index:"index,unique" db_ops:"create"`
+}
+
+func (m *memberDTO) TableName() string {
+	return "members"
+}
+
+func (m *memberDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (m *memberDTO) FieldsPointers() []interface{} {
+	return []interface{}{&m.ID, &m.Code}
+}
+
+func TestMigrator_DataMigrationBeforeIndex(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	ctx := context.Background()
+	db.MustExec("create table members (id integer primary key)")
+	db.MustExec("insert into members (id) values (1), (2)")
+
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&memberDTO{})
+	migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, tableScanner)
+	// уникальный индекс по новой колонке строится только после заполнения значений
+	migrator.RegisterDataMigration(1, "backfill codes", func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, "update members set code = 'm' || id")
+		return err
+	})
+
+	plan, err := migrator.Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[0].Kind != migrate.StepAddColumn || plan.Steps[1].Kind != migrate.StepCreateIndex {
+		t.Fatalf("Plan() steps = %+v, want add_column and create_index", plan.Steps)
+	}
+	if err = migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []string
+	if err = db.Select(&codes, "select code from members order by id"); err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 2 || codes[0] != "m1" || codes[1] != "m2" {
+		t.Errorf("codes = %v, want [m1 m2]", codes)
+	}
+	history, err := migrator.History(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Version != 1 {
+		t.Errorf("History() = %+v, want version 1", history)
+	}
+}
+
+func TestMigrator_ConcurrentDataMigrations(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "data.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	ctx := context.Background()
+	var applied int32
+	started, release := make(chan struct{}), make(chan struct{})
+
+	newMigrator := func(block bool) *migrate.Migrator {
+		db := sqlx.MustOpen("sqlite3", dsn)
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() { _ = db.Close() })
+		migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, scanner.NewTableScanner())
+		migrator.RegisterDataMigration(1, "once", func(ctx context.Context, tx *sqlx.Tx) error {
+			atomic.AddInt32(&applied, 1)
+			if block {
+				close(started)
+				<-release
+			}
+			return nil
+		})
+		return migrator
+	}
+	first, second := newMigrator(true), newMigrator(false)
+
+	firstErr := make(chan error, 1)
+	go func() { firstErr <- first.Migrate(ctx) }()
+	<-started
+	// второй мигратор видит миграцию ожидающей и ждет транзакцию первого на записи в историю
+	secondErr := make(chan error, 1)
+	go func() { secondErr <- second.Migrate(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	if err := <-firstErr; err != nil {
+		t.Fatalf("first Migrate() error = %v", err)
+	}
+	if err := <-secondErr; err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&applied); n != 1 {
+		t.Errorf("data migration applied %d times, want 1", n)
+	}
+}
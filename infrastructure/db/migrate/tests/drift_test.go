@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// newDriftDB база sqlite в памяти с одним соединением, чтобы присоединенные базы сохранялись
+func newDriftDB(t *testing.T, statements ...string) *sqlx.DB {
+	t.Helper()
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	for _, statement := range statements {
+		db.MustExec(statement)
+	}
+
+	return db
+}
+
+func TestMigrator_DriftTypes(t *testing.T) {
+	tests := []struct {
+		expected string
+		actual   string
+		mismatch bool
+	}{
+		{expected: "integer primary key", actual: "INTEGER"},
+		{expected: "bigserial primary key", actual: "bigint"},
+		{expected: "varchar(50)", actual: "character varying(50)"},
+		{expected: "varchar(100)", actual: "varchar(50)", mismatch: true},
+		{expected: "varchar", actual: "varchar(20)"},
+		{expected: "numeric(10,2)", actual: "decimal(10,2)"},
+		{expected: "numeric(10,2)", actual: "numeric(10,3)", mismatch: true},
+		{expected: "timestamp", actual: "datetime"},
+		{expected: "text", actual: "varchar(255)", mismatch: true},
+		{expected: "boolean", actual: "integer", mismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			fmt.Sprintf("%s vs %s", tt.expected, tt.actual), func(t *testing.T) {
+				db := newDriftDB(t, fmt.Sprintf("create table things (value %s)", tt.actual))
+				snapshot := migrate.Snapshot{
+					Tables: []migrate.TableSnapshot{{Name: "things", Fields: []migrate.FieldSnapshot{{Name: "value", Type: tt.expected}}}},
+				}
+				migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, migrate.SnapshotScanner(snapshot))
+				report, err := migrator.Drift(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := report.HasDrift(); got != tt.mismatch {
+					t.Errorf("Drift() = %v, want mismatch %v", report, tt.mismatch)
+				}
+				if tt.mismatch && (len(report.Drifts) != 1 || report.Drifts[0].Kind != migrate.DriftTypeMismatch) {
+					t.Errorf("Drift() = %v, want one %s", report, migrate.DriftTypeMismatch)
+				}
+			},
+		)
+	}
+}
+
+func TestMigrator_DriftIndexes(t *testing.T) {
+	db := newDriftDB(
+		t,
+		"create table users (id integer primary key, email varchar(100), name varchar(50), age integer)",
+		"create index users_email_idx on users (email)",
+		"create index users_name_age on users (name, age)",
+	)
+	snapshot := migrate.Snapshot{
+		Tables: []migrate.TableSnapshot{
+			{
+				Name: "users",
+				Fields: []migrate.FieldSnapshot{
+					{Name: "id", Type: "integer primary key"},
+					{Name: "email", Type: "varchar(100)"},
+					{Name: "name", Type: "varchar(50)"},
+					{Name: "age", Type: "integer"},
+				},
+				Constraints: []migrate.ConstraintSnapshot{
+					{Name: "users_email_idx", Field: "email", Unique: true},
+					{Name: "users_age_idx", Field: "age"},
+				},
+			},
+		},
+	}
+	migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, migrate.SnapshotScanner(snapshot))
+	report, err := migrator.Drift(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []migrate.Drift{
+		{Kind: migrate.DriftMissingIndex, Table: "users", Name: "users_age_idx", Expected: "index (age)"},
+		{Kind: migrate.DriftIndexMismatch, Table: "users", Name: "users_email_idx", Expected: "unique index (email)", Actual: "index (email)"},
+		// колонки индекса перечисляются в порядке ключа, а не колонок таблицы
+		{Kind: migrate.DriftExtraIndex, Table: "users", Name: "users_name_age", Actual: "index (name,age)"},
+	}
+	if len(report.Drifts) != len(want) {
+		t.Fatalf("Drift() = %v, want %v", report.Drifts, want)
+	}
+	for i := range want {
+		if report.Drifts[i] != want[i] {
+			t.Errorf("Drift()[%d] = %v, want %v", i, report.Drifts[i], want[i])
+		}
+	}
+}
+
+func TestMigrator_DriftSchema(t *testing.T) {
+	// одноименные таблицы в основной и присоединенной базах отличаются индексами
+	db := newDriftDB(
+		t,
+		"create table users (id integer primary key, email varchar(100))",
+		"create index users_email_idx on users (email)",
+		"attach database ':memory:' as aux",
+		"create table aux.users (id integer primary key, email varchar(100))",
+	)
+	snapshot := migrate.Snapshot{
+		Tables: []migrate.TableSnapshot{
+			{
+				Name: "users",
+				Fields: []migrate.FieldSnapshot{
+					{Name: "id", Type: "integer primary key"},
+					{Name: "email", Type: "varchar(100)"},
+				},
+				Constraints: []migrate.ConstraintSnapshot{{Name: "users_email_idx", Field: "email"}},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	report, err := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, migrate.SnapshotScanner(snapshot)).Drift(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasDrift() {
+		t.Errorf("Drift() main = %v, want none", report)
+	}
+
+	report, err = migrate.NewMigrator(db, utils.DB{Driver: "sqlite3", Schema: "aux"}, migrate.SnapshotScanner(snapshot)).Drift(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Drifts) != 1 || report.Drifts[0].Kind != migrate.DriftMissingIndex {
+		t.Errorf("Drift() aux = %v, want one %s", report, migrate.DriftMissingIndex)
+	}
+}
+
+type labelDTO struct {
+	ID   int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Name string `db:"name" db_type:"varchar(50)" db_ops:"create"`
+}
+
+func (l *labelDTO) TableName() string {
+	return "labels"
+}
+
+func (l *labelDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (l *labelDTO) FieldsPointers() []interface{} {
+	return []interface{}{&l.ID, &l.Name}
+}
+
+func TestMigrator_QuotedSchema(t *testing.T) {
+	// имя схемы, совпадающее с ключевым словом, работает только в кавычках
+	db := newDriftDB(t, `attach database ':memory:' as "group"`)
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&labelDTO{})
+	migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3", Schema: "group"}, tableScanner)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := migrator.Migrate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.MustExec(`insert into "group".labels (name) values ('a')`)
+
+	ddl := migrate.ExportDDL(tableScanner, utils.DB{Driver: "postgres", Schema: "Billing"})
+	for _, want := range []string{`create schema if not exists "Billing"`, `create table "Billing".labels`} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("ExportDDL() = %s, want %s", ddl, want)
+		}
+	}
+}
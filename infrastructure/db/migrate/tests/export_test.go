@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/genstorage/models"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&models.TestDTO{}, &models.BaseDTO{})
+	snapshot := migrate.ExportSnapshot(tableScanner)
+
+	var buf bytes.Buffer
+	if err := migrate.WriteSnapshot(&buf, snapshot); err != nil {
+		t.Fatalf("WriteSnapshot() error = %v", err)
+	}
+	got, err := migrate.ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, snapshot) {
+		t.Errorf("ReadSnapshot() got = %v, want %v", got, snapshot)
+	}
+	if changes := migrate.CompareSnapshots(snapshot, got); len(changes) > 0 {
+		t.Errorf("CompareSnapshots() got = %v, want no changes", changes)
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	old := migrate.Snapshot{
+		Tables: []migrate.TableSnapshot{
+			{
+				Name: "users",
+				Fields: []migrate.FieldSnapshot{
+					{Name: "id", Type: "bigserial primary key"},
+					{Name: "name", Type: "varchar(255)"},
+					{Name: "legacy", Type: "text"},
+				},
+				Constraints: []migrate.ConstraintSnapshot{
+					{Name: "users_name_idx", Field: "name"},
+				},
+			},
+			{Name: "sessions"},
+		},
+	}
+	updated := migrate.Snapshot{
+		Tables: []migrate.TableSnapshot{
+			{
+				Name: "users",
+				Fields: []migrate.FieldSnapshot{
+					{Name: "id", Type: "bigserial primary key"},
+					{Name: "name", Type: "varchar(100)"},
+					{Name: "email", Type: "varchar(255)"},
+				},
+				Constraints: []migrate.ConstraintSnapshot{
+					{Name: "users_name_idx", Field: "name", Unique: true},
+				},
+			},
+			{Name: "orders"},
+		},
+	}
+
+	want := []migrate.ChangeKind{
+		migrate.TableAdded,
+		migrate.TableRemoved,
+		migrate.FieldAdded,
+		migrate.FieldRemoved,
+		migrate.FieldChanged,
+		migrate.ConstraintChanged,
+	}
+	changes := migrate.CompareSnapshots(old, updated)
+	var got []migrate.ChangeKind
+	for i := range changes {
+		got = append(got, changes[i].Kind)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareSnapshots() got = %v, want %v", changes, want)
+	}
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+)
+
+func TestPlan_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   string
+		step     migrate.Step
+		wantRule string
+	}{
+		{
+			name:   "not null without default",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepAddColumn,
+				Table:      "users",
+				Statements: []string{"alter table users\n\tadd uuid char(36) not null"},
+			},
+			wantRule: migrate.RuleNotNullWithoutDefault,
+		},
+		{
+			name:   "not null with default",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepAddColumn,
+				Table:      "users",
+				Statements: []string{"alter table users add created_at timestamp default (now()) not null"},
+			},
+		},
+		{
+			name:   "not null on new table",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepCreateTable,
+				Table:      "users",
+				Statements: []string{"alter table users add uuid char(36) not null"},
+			},
+		},
+		{
+			name:   "index on large table",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepAddColumn,
+				Table:      "users",
+				Statements: []string{"create index users_uuid_idx on users (uuid)"},
+				Rows:       1000000,
+			},
+			wantRule: migrate.RuleNonConcurrentIndex,
+		},
+		{
+			name:   "index on small table",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepAddColumn,
+				Table:      "users",
+				Statements: []string{"create index users_uuid_idx on users (uuid)"},
+				Rows:       10,
+			},
+		},
+		{
+			name:   "type narrowing",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users alter column name type varchar(10)"},
+				Columns: map[string]migrate.Column{
+					"name": {Name: "name", Type: "character varying", Length: 255},
+				},
+			},
+			wantRule: migrate.RuleTypeNarrowing,
+		},
+		{
+			name:   "type widening",
+			driver: "mysql",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users modify column id bigint"},
+				Columns: map[string]migrate.Column{
+					"id": {Name: "id", Type: "int"},
+				},
+			},
+		},
+		{
+			name:   "drop column",
+			driver: "mysql",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users drop column name"},
+			},
+			wantRule: migrate.RuleDropColumn,
+		},
+		{
+			name:   "integer narrowing",
+			driver: "mysql",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users modify age smallint"},
+				Columns: map[string]migrate.Column{
+					"age": {Name: "age", Type: "bigint"},
+				},
+			},
+			wantRule: migrate.RuleTypeNarrowing,
+		},
+		{
+			name:   "text to varchar",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users alter column bio set data type varchar(100) using bio::varchar(100)"},
+				Columns: map[string]migrate.Column{
+					"bio": {Name: "bio", Type: "text"},
+				},
+			},
+			wantRule: migrate.RuleTypeNarrowing,
+		},
+		{
+			name:   "drop column if exists",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users drop column if exists name"},
+			},
+			wantRule: migrate.RuleDropColumn,
+		},
+		{
+			name:   "drop constraint",
+			driver: "postgres",
+			step: migrate.Step{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users drop constraint users_name_key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				plan := &migrate.Plan{Driver: tt.driver, Steps: []migrate.Step{tt.step}}
+				report := plan.Lint()
+				if tt.wantRule == "" {
+					if len(report.Issues) > 0 {
+						t.Errorf("Lint() got issues %v, want none", report)
+					}
+					return
+				}
+				if len(report.Issues) != 1 || report.Issues[0].Rule != tt.wantRule {
+					t.Errorf("Lint() got %v, want rule %s", report, tt.wantRule)
+				}
+				if !report.HasErrors() {
+					t.Errorf("HasErrors() got false, want true")
+				}
+			},
+		)
+	}
+}
+
+func TestPlan_LintNarrowingUnknownColumn(t *testing.T) {
+	plan := &migrate.Plan{
+		Driver: "postgres",
+		Steps: []migrate.Step{
+			{
+				Kind:       migrate.StepCustom,
+				Table:      "users",
+				Statements: []string{"alter table users alter column name type varchar(10)"},
+			},
+		},
+	}
+	report := plan.Lint()
+	if len(report.Issues) != 1 || report.Issues[0].Rule != migrate.RuleTypeNarrowing || report.Issues[0].Level != migrate.LevelWarning {
+		t.Errorf("Lint() got %v, want %s warning", report, migrate.RuleTypeNarrowing)
+	}
+	if report.HasErrors() {
+		t.Errorf("HasErrors() got true, want false")
+	}
+}
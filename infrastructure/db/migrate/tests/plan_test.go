@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/migrate"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type accountDTO struct {
+	ID    int    `db:"id" db_type:"integer primary key" db_ops:"id"`
+	Email string `db:"email" db_type:"varchar(100)" db_index:"index,unique" db_ops:"create"`
+}
+
+func (a *accountDTO) TableName() string {
+	return "accounts"
+}
+
+func (a *accountDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (a *accountDTO) FieldsPointers() []interface{} {
+	return []interface{}{&a.ID, &a.Email}
+}
+
+func TestMigrator_RecreatesMissingIndex(t *testing.T) {
+	db := sqlx.MustOpen("sqlite3", ":memory:")
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	ctx := context.Background()
+	// таблица без индекса с повторяющимися значениями: построение уникального индекса падает
+	db.MustExec("create table accounts (id integer primary key, email varchar(100))")
+	db.MustExec("insert into accounts (email) values ('a@example.com'), ('a@example.com')")
+
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&accountDTO{})
+	migrator := migrate.NewMigrator(db, utils.DB{Driver: "sqlite3"}, tableScanner)
+
+	plan, err := migrator.Plan(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 1 || plan.Steps[0].Kind != migrate.StepCreateIndex {
+		t.Fatalf("Plan() steps = %+v, want one %s step", plan.Steps, migrate.StepCreateIndex)
+	}
+	if err = migrator.Migrate(ctx); err == nil {
+		t.Fatal("Migrate() with duplicates error = nil, want unique index error")
+	}
+
+	// повторный запуск после исправления данных создает индекс
+	db.MustExec("delete from accounts where id = 2")
+	if err = migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err = db.Get(&count, "select count(*) from pragma_index_list('accounts') where name = 'accounts_email_idx'"); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("index accounts_email_idx count = %d, want 1", count)
+	}
+	if plan, err = migrator.Plan(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Steps) != 0 {
+		t.Errorf("Plan() after rerun steps = %+v, want none", plan.Steps)
+	}
+}
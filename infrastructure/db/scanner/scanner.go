@@ -12,6 +12,11 @@ const (
 	Update    = "update"
 	Upsert    = "upsert"
 	Conflict  = "conflict"
+	ID        = "id"
+	// Autogen поля, значения которых генерирует база данных и которые
+	// заполняются в сущности после вставки: db_ops:"autogen", тег db_autogen
+	// или db_ops:"id" без create, то есть ключ, который не передается при вставке
+	Autogen = "autogen"
 )
 
 // Scanner интерфейс для сканирования таблиц
@@ -42,7 +47,9 @@ type Table struct {
 	FieldsMap       map[string]*Field
 	Constraints     []Constraint
 	OperationFields map[string][]*Field
-	Entity          Tabler
+	// Relations связи с другими таблицами из тегов db_rel по имени поля структуры
+	Relations map[string]*Relation
+	Entity    Tabler
 }
 
 // TableScanner сканер таблиц
@@ -68,6 +75,7 @@ func (t *TableScanner) RegisterTable(entities ...Tabler) {
 			Name:            name,
 			FieldsMap:       make(map[string]*Field),
 			OperationFields: make(map[string][]*Field),
+			Relations:       make(map[string]*Relation),
 			Entity:          entity,
 		}
 		reflected := reflect.TypeOf(entity).Elem()
@@ -78,6 +86,12 @@ func (t *TableScanner) RegisterTable(entities ...Tabler) {
 			// Get the structField tag value
 			fieldName := structField.Tag.Get("db")
 
+			if relationRaw := structField.Tag.Get("db_rel"); relationRaw != "" {
+				if relation := parseRelation(i, structField, relationRaw); relation != nil {
+					table.Relations[relation.Name] = relation
+				}
+				continue
+			}
 			if fieldName == "" || fieldName == "-" {
 				continue
 			}
@@ -101,9 +115,22 @@ func (t *TableScanner) RegisterTable(entities ...Tabler) {
 						field.Constraint.Index = true
 					case "unique":
 						field.Constraint.Unique = true
+					case "concurrently":
+						field.Constraint.Concurrently = true
 					}
 				}
 			}
+			if filterRaw, ok := structField.Tag.Lookup("db_filter"); ok {
+				field.Filters = []string{}
+				if filterRaw != "-" {
+					field.Filters = strings.Split(filterRaw, ",")
+				}
+			}
+			if fkRaw := structField.Tag.Get("db_fk"); fkRaw != "" {
+				if i := strings.IndexByte(fkRaw, '.'); i > 0 {
+					field.ForeignKey = &ForeignKey{Table: fkRaw[:i], Column: fkRaw[i+1:]}
+				}
+			}
 			if field.Constraint.Index {
 				field.Constraint.Field = field
 				table.Constraints = append(table.Constraints, field.Constraint)
@@ -113,11 +140,30 @@ func (t *TableScanner) RegisterTable(entities ...Tabler) {
 
 			opsRaw := structField.Tag.Get("db_ops")
 			ops := strings.Split(opsRaw, ",")
+			autogen, id, create := false, false, false
 			if opsRaw != "" {
 				for j := range ops {
+					switch ops[j] {
+					case Autogen:
+						autogen = true
+						continue
+					case ID:
+						id = true
+					case Create:
+						create = true
+					}
 					table.OperationFields[ops[j]] = append(table.OperationFields[ops[j]], field)
 				}
 			}
+			if id && !create {
+				autogen = true
+			}
+			if autogenRaw, ok := structField.Tag.Lookup("db_autogen"); ok && autogenRaw != "false" {
+				autogen = true
+			}
+			if autogen {
+				table.OperationFields[Autogen] = append(table.OperationFields[Autogen], field)
+			}
 
 			table.OperationFields[AllFields] = append(table.OperationFields[AllFields], field)
 		}
@@ -168,11 +214,88 @@ type Field struct {
 	Constraint Constraint
 	Table      *Table
 	Pointer    interface{}
+	// Filters операторы фильтрации и "sort", разрешенные для поля в запросах API.
+	// nil (тега db_filter нет) разрешает все операторы и сортировку,
+	// пустой срез (db_filter:"-") запрещает фильтрацию и сортировку
+	Filters []string
+	// ForeignKey ссылка на колонку другой таблицы из тега db_fk:"table.column"
+	ForeignKey *ForeignKey
+}
+
+// ForeignKey ссылка поля на колонку другой таблицы
+type ForeignKey struct {
+	Table  string
+	Column string
+}
+
+// RelationKind тип связи
+type RelationKind string
+
+const (
+	HasOne     RelationKind = "has_one"
+	HasMany    RelationKind = "has_many"
+	ManyToMany RelationKind = "many_to_many"
+)
+
+// Relation связь сущности с другой таблицей, поле структуры имеет тип
+// связанной сущности (has_one) или среза сущностей (has_many, many_to_many):
+//
+//	db_rel:"has_one,user_id" и db_rel:"has_many,user_id", где user_id колонка связанной таблицы
+//	db_rel:"many_to_many,user_roles,user_id,role_id", где user_roles таблица связи
+type Relation struct {
+	IDx  int
+	Name string
+	Kind RelationKind
+	// Entity сущность связанной таблицы
+	Entity Tabler
+	// ForeignKey колонка связанной таблицы или таблицы связи, ссылающаяся на сущность
+	ForeignKey string
+	// JoinTable таблица связи many_to_many
+	JoinTable string
+	// References колонка таблицы связи, ссылающаяся на связанную таблицу
+	References string
+}
+
+// parseRelation разбор тега db_rel, при неверном теге или типе поля связь не регистрируется
+func parseRelation(idx int, structField reflect.StructField, raw string) *Relation {
+	pieces := strings.Split(raw, ",")
+	relation := &Relation{IDx: idx, Name: structField.Name, Kind: RelationKind(pieces[0])}
+	switch {
+	case (relation.Kind == HasOne || relation.Kind == HasMany) && len(pieces) == 2:
+		relation.ForeignKey = pieces[1]
+	case relation.Kind == ManyToMany && len(pieces) == 4:
+		relation.JoinTable, relation.ForeignKey, relation.References = pieces[1], pieces[2], pieces[3]
+	default:
+		return nil
+	}
+
+	elem := structField.Type
+	if relation.Kind != HasOne {
+		if elem.Kind() != reflect.Slice {
+			return nil
+		}
+		elem = elem.Elem()
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	entity, ok := reflect.New(elem).Interface().(Tabler)
+	if !ok {
+		return nil
+	}
+	relation.Entity = entity
+
+	return relation
 }
 
 // Constraint структура ограничения
 type Constraint struct {
 	Index  bool
 	Unique bool
-	Field  *Field
+	// Concurrently построение индекса без блокировки записи (только postgres)
+	Concurrently bool
+	Field        *Field
 }
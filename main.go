@@ -23,6 +23,11 @@ func init() {
 }
 
 func main() {
+	// Команда diff сравнивает схему базы данных со снимком
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+
 	// Создаем новый флаг для вывода справки
 	helpFlag := flag.Bool("h", false, "Show help")
 	helpLongFlag := flag.Bool("help", false, "Show help")
@@ -73,6 +78,7 @@ func printHelp() {
 	fmt.Println("Usage:")
 	fmt.Println("  app -h           Show help")
 	fmt.Println("  app --entity=<file> --output=<directory>")
+	fmt.Println("  app diff --snapshot=<file> --driver=<driver> --name=<database> [db flags]")
 	fmt.Println()
 	fmt.Println("Flags:")
 	flag.PrintDefaults()
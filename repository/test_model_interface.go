@@ -2,15 +2,24 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/Alexandrhub/cli-orm-gen/db/dao"
 	"github.com/Alexandrhub/cli-orm-gen/genstorage/models"
 	"github.com/Alexandrhub/cli-orm-gen/utils"
 )
 
 type ITestDTO interface {
-	Create(ctx context.Context, dto models.TestDTO) error
+	Create(ctx context.Context, dto models.TestDTO) (models.TestDTO, error)
+	CreateMany(ctx context.Context, dto []models.TestDTO) error
 	Upsert(ctx context.Context, dto []models.TestDTO) error
 	GetCount(ctx context.Context, dto models.TestDTO, condition utils.Condition) (uint64, error)
+	CountByCreatedAt(ctx context.Context, condition utils.Condition) ([]dao.GroupCount[time.Time], error)
+	CountByUpdatedAt(ctx context.Context, condition utils.Condition) ([]dao.GroupCount[time.Time], error)
+	Get(ctx context.Context, condition utils.Condition) (models.TestDTO, error)
 	List(ctx context.Context, condition utils.Condition) ([]models.TestDTO, error)
+	Page(ctx context.Context, condition utils.Condition, cursor string, size uint64) ([]models.TestDTO, dao.Cursors, error)
 	Update(ctx context.Context, dto models.TestDTO, condition utils.Condition) error
+	Delete(ctx context.Context, condition utils.Condition) error
+	SoftDelete(ctx context.Context, condition utils.Condition) error
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Alexandrhub/cli-orm-gen/db/dao"
 	"github.com/Alexandrhub/cli-orm-gen/genstorage/models"
@@ -18,14 +19,33 @@ func NewTestDTOStorage(dto *dao.DAO) *TestDTOStorage {
 	return &TestDTOStorage{dto: dto}
 }
 
-func (t *TestDTOStorage) Create(ctx context.Context, dto models.TestDTO) error {
-	return t.dto.Create(ctx, &dto)
+func (t *TestDTOStorage) Create(ctx context.Context, dto models.TestDTO) (models.TestDTO, error) {
+	err := t.dto.Create(ctx, &dto)
+	if err != nil {
+		return models.TestDTO{}, fmt.Errorf("testdto storage: Create: %w", err)
+	}
+
+	return dto, nil
+}
+
+func (t *TestDTOStorage) CreateMany(ctx context.Context, dto []models.TestDTO) error {
+	var entities []scanner.Tabler
+	for i := range dto {
+		entities = append(entities, &dto[i])
+	}
+
+	err := t.dto.CreateMany(ctx, entities)
+	if err != nil {
+		return fmt.Errorf("testdto storage: CreateMany: %w", err)
+	}
+
+	return nil
 }
 
 func (t *TestDTOStorage) Upsert(ctx context.Context, dto []models.TestDTO) error {
 	var entities []scanner.Tabler
-	for _, d := range dto {
-		entities = append(entities, &d)
+	for i := range dto {
+		entities = append(entities, &dto[i])
 	}
 
 	return t.dto.Upsert(
@@ -38,12 +58,42 @@ func (t *TestDTOStorage) GetCount(ctx context.Context, dto models.TestDTO, condi
 	var count uint64
 	count, err := t.dto.GetCount(ctx, &dto, condition)
 	if err != nil {
-		return 0, fmt.Errorf("testdto storage: GetCount not found")
+		return 0, fmt.Errorf("testdto storage: GetCount: %w", err)
 	}
 
 	return count, nil
 }
 
+func (t *TestDTOStorage) CountByCreatedAt(ctx context.Context, condition utils.Condition) ([]dao.GroupCount[time.Time], error) {
+	var table models.TestDTO
+	counts, err := dao.CountBy[time.Time](ctx, t.dto, &table, "created_at", condition)
+	if err != nil {
+		return nil, fmt.Errorf("testdto storage: CountByCreatedAt: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (t *TestDTOStorage) CountByUpdatedAt(ctx context.Context, condition utils.Condition) ([]dao.GroupCount[time.Time], error) {
+	var table models.TestDTO
+	counts, err := dao.CountBy[time.Time](ctx, t.dto, &table, "updated_at", condition)
+	if err != nil {
+		return nil, fmt.Errorf("testdto storage: CountByUpdatedAt: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (t *TestDTOStorage) Get(ctx context.Context, condition utils.Condition) (models.TestDTO, error) {
+	var dto models.TestDTO
+	err := t.dto.Get(ctx, &dto, condition)
+	if err != nil {
+		return models.TestDTO{}, fmt.Errorf("testdto storage: Get: %w", err)
+	}
+
+	return dto, nil
+}
+
 func (t *TestDTOStorage) List(ctx context.Context, condition utils.Condition) ([]models.TestDTO, error) {
 	var list []models.TestDTO
 	var table models.TestDTO
@@ -55,6 +105,17 @@ func (t *TestDTOStorage) List(ctx context.Context, condition utils.Condition) ([
 	return list, nil
 }
 
+func (t *TestDTOStorage) Page(ctx context.Context, condition utils.Condition, cursor string, size uint64) ([]models.TestDTO, dao.Cursors, error) {
+	var list []models.TestDTO
+	var table models.TestDTO
+	cursors, err := t.dto.Page(ctx, &list, &table, condition, cursor, size)
+	if err != nil {
+		return nil, dao.Cursors{}, fmt.Errorf("testdto storage: Page: %w", err)
+	}
+
+	return list, cursors, nil
+}
+
 func (t *TestDTOStorage) Update(ctx context.Context, dto models.TestDTO, condition utils.Condition) error {
 	return t.dto.Update(
 		ctx,
@@ -63,3 +124,13 @@ func (t *TestDTOStorage) Update(ctx context.Context, dto models.TestDTO, conditi
 		"update",
 	)
 }
+
+func (t *TestDTOStorage) Delete(ctx context.Context, condition utils.Condition) error {
+	var table models.TestDTO
+	return t.dto.Delete(ctx, &table, condition)
+}
+
+func (t *TestDTOStorage) SoftDelete(ctx context.Context, condition utils.Condition) error {
+	var table models.TestDTO
+	return t.dto.SoftDelete(ctx, &table, condition)
+}
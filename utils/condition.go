@@ -2,12 +2,22 @@ package utils
 
 // Condition структура условий
 type Condition struct {
-	Equal       map[string]interface{}
-	NotEqual    map[string]interface{}
+	// Equal условия равенства, значение nil означает IS NULL, срез означает IN
+	Equal map[string]interface{}
+	// NotEqual условия неравенства, значение nil означает IS NOT NULL, срез означает NOT IN
+	NotEqual map[string]interface{}
+	// Where выражения, объединяемые через AND с Equal и NotEqual
+	Where       []Expr
 	Order       []*Order
 	LimitOffset *LimitOffset
-	ForUpdate   bool
-	Upsert      bool
+	// ForUpdate блокировка выбранных строк FOR UPDATE, выполняется только в транзакции
+	ForUpdate bool
+	Upsert    bool
+}
+
+// IsEmpty отсутствие условий фильтрации
+func (c Condition) IsEmpty() bool {
+	return len(c.Equal) == 0 && len(c.NotEqual) == 0 && len(c.Where) == 0
 }
 
 // Order структура сортировки
@@ -1,5 +1,7 @@
 package utils
 
+import "strings"
+
 // DB структура базы данных
 type DB struct {
 	Net      string
@@ -11,4 +13,46 @@ type DB struct {
 	MaxConn  int
 	Port     string
 	Timeout  int
+	// Schema схема postgres или база данных mysql, в которой размещаются таблицы,
+	// по умолчанию public для postgres и Name для mysql
+	Schema string
+	// CursorSecret ключ подписи курсоров постраничной выборки, без ключа
+	// используется случайный ключ, курсоры действительны до перезапуска и DAO
+	// выводит предупреждение при первой постраничной выборке
+	CursorSecret string
+}
+
+// SchemaName получение схемы, в которой размещаются таблицы
+func (d DB) SchemaName() string {
+	if d.Schema != "" {
+		return d.Schema
+	}
+	switch d.Driver {
+	case "postgres":
+		return "public"
+	case "mysql":
+		return d.Name
+	}
+
+	return ""
+}
+
+// QualifiedName получение имени таблицы с указанием схемы в кавычках,
+// если схема не задана явно, имя таблицы возвращается без изменений
+func (d DB) QualifiedName(table string) string {
+	if d.Schema == "" {
+		return table
+	}
+
+	return d.QuoteIdentifier(d.Schema) + "." + table
+}
+
+// QuoteIdentifier идентификатор в кавычках диалекта драйвера: обратные кавычки mysql,
+// двойные кавычки остальных драйверов, регистр имени сохраняется
+func (d DB) QuoteIdentifier(name string) string {
+	if d.Driver == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
 }
@@ -0,0 +1,111 @@
+package utils
+
+// Operator оператор выражения условия
+type Operator string
+
+const (
+	OpEq        Operator = "="
+	OpNotEq     Operator = "<>"
+	OpGt        Operator = ">"
+	OpGte       Operator = ">="
+	OpLt        Operator = "<"
+	OpLte       Operator = "<="
+	OpLike      Operator = "LIKE"
+	OpILike     Operator = "ILIKE"
+	OpIn        Operator = "IN"
+	OpNotIn     Operator = "NOT IN"
+	OpIsNull    Operator = "IS NULL"
+	OpIsNotNull Operator = "IS NOT NULL"
+	OpBetween   Operator = "BETWEEN"
+	OpAnd       Operator = "AND"
+	OpOr        Operator = "OR"
+	OpNot       Operator = "NOT"
+)
+
+// Expr выражение условия: сравнение поля или группа выражений
+type Expr struct {
+	Op    Operator
+	Field string
+	Args  []interface{}
+	Exprs []Expr
+}
+
+// Eq поле равно значению
+func Eq(field string, value interface{}) Expr {
+	return Expr{Op: OpEq, Field: field, Args: []interface{}{value}}
+}
+
+// NotEq поле не равно значению
+func NotEq(field string, value interface{}) Expr {
+	return Expr{Op: OpNotEq, Field: field, Args: []interface{}{value}}
+}
+
+// Gt поле больше значения
+func Gt(field string, value interface{}) Expr {
+	return Expr{Op: OpGt, Field: field, Args: []interface{}{value}}
+}
+
+// Gte поле больше или равно значению
+func Gte(field string, value interface{}) Expr {
+	return Expr{Op: OpGte, Field: field, Args: []interface{}{value}}
+}
+
+// Lt поле меньше значения
+func Lt(field string, value interface{}) Expr {
+	return Expr{Op: OpLt, Field: field, Args: []interface{}{value}}
+}
+
+// Lte поле меньше или равно значению
+func Lte(field string, value interface{}) Expr {
+	return Expr{Op: OpLte, Field: field, Args: []interface{}{value}}
+}
+
+// Like поле соответствует шаблону LIKE
+func Like(field string, pattern string) Expr {
+	return Expr{Op: OpLike, Field: field, Args: []interface{}{pattern}}
+}
+
+// ILike поле соответствует шаблону LIKE без учета регистра
+func ILike(field string, pattern string) Expr {
+	return Expr{Op: OpILike, Field: field, Args: []interface{}{pattern}}
+}
+
+// In поле входит в список, values срез значений
+func In(field string, values interface{}) Expr {
+	return Expr{Op: OpIn, Field: field, Args: []interface{}{values}}
+}
+
+// NotIn поле не входит в список, values срез значений
+func NotIn(field string, values interface{}) Expr {
+	return Expr{Op: OpNotIn, Field: field, Args: []interface{}{values}}
+}
+
+// IsNull поле равно NULL
+func IsNull(field string) Expr {
+	return Expr{Op: OpIsNull, Field: field}
+}
+
+// IsNotNull поле не равно NULL
+func IsNotNull(field string) Expr {
+	return Expr{Op: OpIsNotNull, Field: field}
+}
+
+// Between поле находится в диапазоне включительно
+func Between(field string, from, to interface{}) Expr {
+	return Expr{Op: OpBetween, Field: field, Args: []interface{}{from, to}}
+}
+
+// And все выражения истинны
+func And(exprs ...Expr) Expr {
+	return Expr{Op: OpAnd, Exprs: exprs}
+}
+
+// Or хотя бы одно выражение истинно
+func Or(exprs ...Expr) Expr {
+	return Expr{Op: OpOr, Exprs: exprs}
+}
+
+// Not отрицание выражения
+func Not(expr Expr) Expr {
+	return Expr{Op: OpNot, Exprs: []Expr{expr}}
+}
@@ -0,0 +1,330 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+)
+
+const (
+	// QueryFilter параметр фильтра вида field:op:value, может повторяться
+	QueryFilter = "filter"
+	// QuerySort параметр сортировки вида -created_at,name, минус означает DESC
+	QuerySort = "sort"
+	// QueryLimit параметр количества строк
+	QueryLimit = "limit"
+	// QueryOffset параметр смещения
+	QueryOffset = "offset"
+
+	// FilterSort разрешение сортировки в теге db_filter
+	FilterSort = "sort"
+
+	// DefaultMaxLimit наибольшее значение limit по умолчанию,
+	// оно же используется, если параметр limit не передан
+	DefaultMaxLimit = 100
+)
+
+// QueryOption опция ParseQuery
+type QueryOption func(*queryOptions)
+
+// queryOptions опции ParseQuery
+type queryOptions struct {
+	maxLimit int64
+}
+
+// WithMaxLimit наибольшее значение параметра limit вместо DefaultMaxLimit,
+// 0 снимает ограничение, тогда запрос без limit выбирает все строки
+func WithMaxLimit(limit int64) QueryOption {
+	return func(o *queryOptions) {
+		o.maxLimit = limit
+	}
+}
+
+// filterOperators операторы фильтра запроса API
+var filterOperators = map[string]Operator{
+	"eq":      OpEq,
+	"ne":      OpNotEq,
+	"gt":      OpGt,
+	"gte":     OpGte,
+	"lt":      OpLt,
+	"lte":     OpLte,
+	"like":    OpLike,
+	"ilike":   OpILike,
+	"in":      OpIn,
+	"nin":     OpNotIn,
+	"null":    OpIsNull,
+	"between": OpBetween,
+}
+
+// FieldError ошибка параметра запроса API
+type FieldError struct {
+	Param   string `json:"param"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error описание ошибки
+func (e FieldError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s %s: %s", e.Param, e.Field, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// FieldErrors ошибки параметров запроса API
+type FieldErrors []FieldError
+
+// Error описание ошибок
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for i := range e {
+		messages = append(messages, e[i].Error())
+	}
+
+	return "invalid query: " + strings.Join(messages, "; ")
+}
+
+// ParseQuery преобразование параметров filter, sort, limit и offset в условие,
+// поля и операторы проверяются по зарегистрированным полям таблицы и тегу db_filter.
+// Поле без тега db_filter допускает все операторы и сортировку, поэтому поля,
+// недоступные клиентам API, нужно закрыть тегом db_filter:"-". Значение limit
+// ограничено DefaultMaxLimit или WithMaxLimit и подставляется, если limit не передан.
+// При ошибках возвращается FieldErrors со всеми найденными ошибками
+func ParseQuery(values url.Values, table scanner.Table, opts ...QueryOption) (Condition, error) {
+	o := &queryOptions{maxLimit: DefaultMaxLimit}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var condition Condition
+	var errs FieldErrors
+
+	for _, raw := range values[QueryFilter] {
+		expr, err := parseFilter(raw, table)
+		if err != nil {
+			errs = append(errs, *err)
+			continue
+		}
+		condition.Where = append(condition.Where, expr)
+	}
+
+	for _, raw := range values[QuerySort] {
+		for _, name := range strings.Split(raw, ",") {
+			if name == "" {
+				continue
+			}
+			order := &Order{Field: strings.TrimPrefix(name, "-"), Asc: !strings.HasPrefix(name, "-")}
+			field, ok := table.FieldsMap[order.Field]
+			if !ok {
+				errs = append(errs, FieldError{Param: QuerySort, Field: order.Field, Message: "unknown field"})
+				continue
+			}
+			if !allowed(field, FilterSort) {
+				errs = append(errs, FieldError{Param: QuerySort, Field: order.Field, Message: "sorting is not allowed"})
+				continue
+			}
+			condition.Order = append(condition.Order, order)
+		}
+	}
+
+	for _, param := range []string{QueryLimit, QueryOffset} {
+		raw := values.Get(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			errs = append(errs, FieldError{Param: param, Message: "must be a non-negative integer"})
+			continue
+		}
+		if param == QueryLimit && o.maxLimit > 0 && (value == 0 || value > o.maxLimit) {
+			errs = append(errs, FieldError{Param: param, Message: fmt.Sprintf("must be between 1 and %d", o.maxLimit)})
+			continue
+		}
+		if condition.LimitOffset == nil {
+			condition.LimitOffset = &LimitOffset{}
+		}
+		if param == QueryLimit {
+			condition.LimitOffset.Limit = value
+		} else {
+			condition.LimitOffset.Offset = value
+		}
+	}
+
+	if len(errs) > 0 {
+		return Condition{}, errs
+	}
+	if values.Get(QueryLimit) == "" && o.maxLimit > 0 {
+		if condition.LimitOffset == nil {
+			condition.LimitOffset = &LimitOffset{}
+		}
+		condition.LimitOffset.Limit = o.maxLimit
+	}
+
+	return condition, nil
+}
+
+// parseFilter разбор фильтра вида field:op:value
+func parseFilter(raw string, table scanner.Table) (Expr, *FieldError) {
+	pieces := strings.SplitN(raw, ":", 3)
+	if len(pieces) < 2 {
+		return Expr{}, &FieldError{Param: QueryFilter, Message: fmt.Sprintf("%q must be field:op:value", raw)}
+	}
+	name, opName := pieces[0], pieces[1]
+	field, ok := table.FieldsMap[name]
+	if !ok {
+		return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: "unknown field"}
+	}
+	op, ok := filterOperators[opName]
+	if !ok {
+		return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: fmt.Sprintf("unknown operator %q", opName)}
+	}
+	if !allowed(field, opName) {
+		return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: fmt.Sprintf("operator %q is not allowed", opName)}
+	}
+	if len(pieces) < 3 {
+		return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: "value is required"}
+	}
+	raw = pieces[2]
+
+	if op == OpIsNull {
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: "null value must be true or false"}
+		}
+		if !isNull {
+			return IsNotNull(name), nil
+		}
+		return IsNull(name), nil
+	}
+
+	valueType := fieldValueType(table, field)
+	if op == OpLike || op == OpILike {
+		valueType = reflect.TypeOf("")
+	}
+	rawValues := []string{raw}
+	if op == OpIn || op == OpNotIn || op == OpBetween {
+		rawValues = strings.Split(raw, ",")
+	}
+	if op == OpBetween && len(rawValues) != 2 {
+		return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: "between value must be from,to"}
+	}
+	args := make([]interface{}, 0, len(rawValues))
+	for i := range rawValues {
+		value, err := convertValue(rawValues[i], valueType)
+		if err != nil {
+			return Expr{}, &FieldError{Param: QueryFilter, Field: name, Message: err.Error()}
+		}
+		args = append(args, value)
+	}
+
+	switch op {
+	case OpIn:
+		return In(name, args), nil
+	case OpNotIn:
+		return NotIn(name, args), nil
+	}
+
+	return Expr{Op: op, Field: name, Args: args}, nil
+}
+
+// allowed разрешение оператора или сортировки по тегу db_filter поля
+func allowed(field *scanner.Field, op string) bool {
+	if field.Filters == nil {
+		return true
+	}
+	for i := range field.Filters {
+		if field.Filters[i] == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldValueType тип значения поля сущности, для оберток вида sql.NullInt64
+// используется тип обернутого значения, при неизвестном типе строка
+func fieldValueType(table scanner.Table, field *scanner.Field) reflect.Type {
+	stringType := reflect.TypeOf("")
+	if table.Entity == nil {
+		return stringType
+	}
+	entityType := reflect.TypeOf(table.Entity)
+	for entityType.Kind() == reflect.Ptr {
+		entityType = entityType.Elem()
+	}
+	if entityType.Kind() != reflect.Struct || field.IDx >= entityType.NumField() {
+		return stringType
+	}
+	if valueType := unwrapType(entityType.Field(field.IDx).Type); valueType != nil {
+		return valueType
+	}
+
+	return stringType
+}
+
+// unwrapType базовый тип значения с раскрытием указателей и оберток с полем Valid
+func unwrapType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t.Kind() != reflect.Struct {
+		return t
+	}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if structField.Name == "Valid" || !structField.IsExported() {
+			continue
+		}
+		return unwrapType(structField.Type)
+	}
+
+	return nil
+}
+
+// convertValue преобразование значения параметра к типу поля
+func convertValue(raw string, t reflect.Type) (interface{}, error) {
+	if t == timeType {
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be RFC3339 time", raw)
+		}
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q must be integer", raw)
+		}
+		return value, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q must be unsigned integer", raw)
+		}
+		return value, nil
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%q must be number", raw)
+		}
+		return value, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be true or false", raw)
+		}
+		return value, nil
+	}
+
+	return raw, nil
+}
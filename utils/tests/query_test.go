@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/scanner"
+	"github.com/Alexandrhub/cli-orm-gen/infrastructure/db/types"
+	"github.com/Alexandrhub/cli-orm-gen/utils"
+)
+
+type orderDTO struct {
+	ID        int            `db:"id" db_type:"serial primary key" db_ops:"id"`
+	Status    string         `db:"status" db_type:"varchar(20)" db_filter:"eq,in,sort"`
+	Total     float64        `db:"total" db_type:"numeric(10,2)"`
+	Secret    string         `db:"secret" db_type:"varchar(100)" db_filter:"-"`
+	CreatedAt types.NullTime `db:"created_at" db_type:"timestamp"`
+}
+
+func (o *orderDTO) TableName() string {
+	return "orders"
+}
+
+func (o *orderDTO) OnCreate() []string {
+	return []string{}
+}
+
+func (o *orderDTO) FieldsPointers() []interface{} {
+	return []interface{}{&o.ID, &o.Status, &o.Total, &o.Secret, &o.CreatedAt}
+}
+
+func ordersTable() scanner.Table {
+	tableScanner := scanner.NewTableScanner()
+	tableScanner.RegisterTable(&orderDTO{})
+
+	return tableScanner.Table("orders")
+}
+
+func TestParseQuery(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	values := url.Values{
+		"filter": {"status:in:new,paid", "total:gte:10.5", "created_at:between:2024-01-02T03:04:05Z,2024-01-02T03:04:05Z", "id:null:false"},
+		"sort":   {"-status,id"},
+		"limit":  {"20"},
+		"offset": {"40"},
+	}
+	condition, err := utils.ParseQuery(values, ordersTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := utils.Condition{
+		Where: []utils.Expr{
+			utils.In("status", []interface{}{"new", "paid"}),
+			utils.Gte("total", 10.5),
+			utils.Between("created_at", created, created),
+			utils.IsNotNull("id"),
+		},
+		Order:       []*utils.Order{{Field: "status"}, {Field: "id", Asc: true}},
+		LimitOffset: &utils.LimitOffset{Limit: 20, Offset: 40},
+	}
+	if !reflect.DeepEqual(condition, want) {
+		t.Errorf("ParseQuery() = %+v, want %+v", condition, want)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	values := url.Values{
+		"filter": {"status:gt:new", "secret:eq:x", "total:eq:abc", "missing:eq:1", "total:regex:1", "status"},
+		"sort":   {"total,secret,missing"},
+		"limit":  {"-1"},
+	}
+	_, err := utils.ParseQuery(values, ordersTable())
+
+	var fieldErrs utils.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("ParseQuery() error = %v, want FieldErrors", err)
+	}
+	want := utils.FieldErrors{
+		{Param: "filter", Field: "status", Message: `operator "gt" is not allowed`},
+		{Param: "filter", Field: "secret", Message: `operator "eq" is not allowed`},
+		{Param: "filter", Field: "total", Message: `"abc" must be number`},
+		{Param: "filter", Field: "missing", Message: "unknown field"},
+		{Param: "filter", Field: "total", Message: `unknown operator "regex"`},
+		{Param: "filter", Message: `"status" must be field:op:value`},
+		{Param: "sort", Field: "secret", Message: "sorting is not allowed"},
+		{Param: "sort", Field: "missing", Message: "unknown field"},
+		{Param: "limit", Message: "must be a non-negative integer"},
+	}
+	if !reflect.DeepEqual(fieldErrs, want) {
+		t.Errorf("ParseQuery() errors = %+v, want %+v", fieldErrs, want)
+	}
+}
+
+func TestParseQuery_Limit(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		opts    []utils.QueryOption
+		want    *utils.LimitOffset
+		wantErr string
+	}{
+		{name: "default", values: url.Values{}, want: &utils.LimitOffset{Limit: utils.DefaultMaxLimit}},
+		{name: "offset only", values: url.Values{"offset": {"5"}}, want: &utils.LimitOffset{Limit: utils.DefaultMaxLimit, Offset: 5}},
+		{name: "at max", values: url.Values{"limit": {"100"}}, want: &utils.LimitOffset{Limit: 100}},
+		{name: "over max", values: url.Values{"limit": {"101"}}, wantErr: "must be between 1 and 100"},
+		{name: "zero", values: url.Values{"limit": {"0"}}, wantErr: "must be between 1 and 100"},
+		{
+			name: "custom max", values: url.Values{"limit": {"20"}}, opts: []utils.QueryOption{utils.WithMaxLimit(10)},
+			wantErr: "must be between 1 and 10",
+		},
+		{name: "unlimited", values: url.Values{}, opts: []utils.QueryOption{utils.WithMaxLimit(0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				condition, err := utils.ParseQuery(tt.values, ordersTable(), tt.opts...)
+				if tt.wantErr != "" {
+					var fieldErrs utils.FieldErrors
+					if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Message != tt.wantErr {
+						t.Errorf("ParseQuery() error = %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(condition.LimitOffset, tt.want) {
+					t.Errorf("ParseQuery() LimitOffset = %+v, want %+v", condition.LimitOffset, tt.want)
+				}
+			},
+		)
+	}
+}